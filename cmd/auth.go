@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+const authRealm = "Fake Realm" // Realm of the authentication endpoints
+
+// handleBasicAuth authenticates the request with the user and password given
+// in the path, using HTTP basic authentication.
+func (s *appServer) handleBasicAuth(w http.ResponseWriter, req *http.Request) {
+	expectedUser, expectedPass, ok := strings.Cut(pathParam(req, "/basic-auth/"), "/")
+	if !ok {
+		writeError(w, http.StatusBadRequest, errors.New("user and password must be specified"))
+		return
+	}
+
+	user, pass, ok := req.BasicAuth()
+	if !ok || !secureCompare(user, expectedUser) || !secureCompare(pass, expectedPass) {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Basic realm="%s"`, authRealm))
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"authenticated": false})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"authenticated": true, "user": user})
+}
+
+// handleBearer accepts any bearer token and returns it.
+func (s *appServer) handleBearer(w http.ResponseWriter, req *http.Request) {
+	scheme, token, _ := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") || len(strings.TrimSpace(token)) == 0 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"authenticated": false})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"authenticated": true, "token": strings.TrimSpace(token)})
+}
+
+// handleDigestAuth authenticates the request using HTTP digest
+// authentication (RFC 7616). The path is
+// /digest-auth/{qop}/{user}/{pass}[/{algorithm}], where qop is auth or
+// auth-int and algorithm is MD5 (default) or SHA-256.
+func (s *appServer) handleDigestAuth(w http.ResponseWriter, req *http.Request) {
+	params := strings.Split(pathParam(req, "/digest-auth/"), "/")
+	if len(params) < 3 || len(params) > 4 {
+		writeError(w, http.StatusBadRequest, errors.New("qop, user and password must be specified"))
+		return
+	}
+
+	qop, expectedUser, expectedPass := params[0], params[1], params[2]
+	if qop != "auth" && qop != "auth-int" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported qop: %s", qop))
+		return
+	}
+
+	algorithm := "MD5"
+	if len(params) == 4 {
+		algorithm = strings.ToUpper(params[3])
+	}
+
+	newHash, err := digestHash(algorithm)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	scheme, credentials, _ := strings.Cut(req.Header.Get("Authorization"), " ")
+	if strings.EqualFold(scheme, "Digest") {
+		auth := parseAuthParams(credentials)
+
+		var body []byte
+		if auth["qop"] == "auth-int" {
+			if body, err = readBody(req); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		expected := digestResponse(newHash, auth, req.Method, body, expectedPass)
+		if auth["qop"] == qop && secureCompare(auth["username"], expectedUser) && secureCompare(auth["response"], expected) {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"authenticated": true, "user": expectedUser})
+			return
+		}
+	}
+
+	nonce, opaque := make([]byte, 16), make([]byte, 16)
+	if _, err = rand.Read(nonce); err == nil {
+		_, err = rand.Read(opaque)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="%s", qop="%s", nonce="%x", opaque="%x", algorithm=%s`,
+		authRealm, qop, nonce, opaque, algorithm))
+	writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"authenticated": false})
+}
+
+func digestHash(algorithm string) (func() hash.Hash, error) {
+	switch algorithm {
+	case "MD5":
+		return md5.New, nil
+	case "SHA-256":
+		return sha256.New, nil
+	}
+
+	return nil, fmt.Errorf("unsupported digest algorithm: %s", algorithm)
+}
+
+// digestResponse computes the expected response of a digest authorization.
+func digestResponse(newHash func() hash.Hash, auth map[string]string, method string, body []byte, password string) string {
+	h := func(s string) string {
+		hash := newHash()
+		hash.Write([]byte(s))
+		return hex.EncodeToString(hash.Sum(nil))
+	}
+
+	ha1 := h(auth["username"] + ":" + auth["realm"] + ":" + password)
+
+	ha2 := h(method + ":" + auth["uri"])
+	if auth["qop"] == "auth-int" {
+		ha2 = h(method + ":" + auth["uri"] + ":" + h(string(body)))
+	}
+
+	if len(auth["qop"]) == 0 {
+		return h(ha1 + ":" + auth["nonce"] + ":" + ha2)
+	}
+
+	return h(strings.Join([]string{ha1, auth["nonce"], auth["nc"], auth["cnonce"], auth["qop"], ha2}, ":"))
+}
+
+// parseAuthParams parses the comma separated key=value pairs of an
+// Authorization header, values may be quoted.
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+
+	for len(s) > 0 {
+		var key, value string
+
+		key, s, _ = strings.Cut(s, "=")
+		key = strings.ToLower(strings.TrimSpace(strings.TrimLeft(key, ", ")))
+
+		s = strings.TrimSpace(s)
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+
+			value = b.String()
+			if i < len(s) {
+				i++
+			}
+			s = s[i:]
+		} else {
+			value, s, _ = strings.Cut(s, ",")
+			value = strings.TrimSpace(value)
+		}
+
+		s = strings.TrimLeft(s, ", ")
+
+		if len(key) > 0 {
+			params[key] = value
+		}
+	}
+
+	return params
+}
+
+func secureCompare(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
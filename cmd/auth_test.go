@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// rfc7616Authorization returns the Authorization header of the RFC 7616
+// example (section 3.9.1) with the given algorithm and response.
+func rfc7616Authorization(algorithm string, response string) string {
+	return `Digest username="Mufasa", realm="http-auth@example.org", uri="/dir/index.html", algorithm=` + algorithm +
+		`, nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", nc=00000001, cnonce="f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ"` +
+		`, qop=auth, response="` + response + `", opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"`
+}
+
+func TestDigestResponse(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		newHash   func() hash.Hash
+		response  string
+	}{
+		{"MD5", md5.New, "8ca523f5e9506fed4657c9700eebdbec"},
+		{"SHA-256", sha256.New, "753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			_, credentials, _ := strings.Cut(rfc7616Authorization(tt.algorithm, tt.response), " ")
+			auth := parseAuthParams(credentials)
+
+			if got := digestResponse(tt.newHash, auth, http.MethodGet, nil, "Circle of Life"); got != tt.response {
+				t.Errorf("digestResponse() = %s, want %s", got, tt.response)
+			}
+		})
+	}
+}
+
+func TestHandleDigestAuth(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		status        int
+	}{
+		{"MD5", "/digest-auth/auth/Mufasa/Circle%20of%20Life", rfc7616Authorization("MD5", "8ca523f5e9506fed4657c9700eebdbec"), http.StatusOK},
+		{"SHA-256", "/digest-auth/auth/Mufasa/Circle%20of%20Life/SHA-256", rfc7616Authorization("SHA-256", "753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1"), http.StatusOK},
+		{"wrong password", "/digest-auth/auth/Mufasa/secret", rfc7616Authorization("MD5", "8ca523f5e9506fed4657c9700eebdbec"), http.StatusUnauthorized},
+		{"wrong qop", "/digest-auth/auth-int/Mufasa/Circle%20of%20Life", rfc7616Authorization("MD5", "8ca523f5e9506fed4657c9700eebdbec"), http.StatusUnauthorized},
+		{"no credentials", "/digest-auth/auth/Mufasa/Circle%20of%20Life", "", http.StatusUnauthorized},
+		{"invalid qop", "/digest-auth/none/Mufasa/Circle%20of%20Life", "", http.StatusBadRequest},
+		{"invalid algorithm", "/digest-auth/auth/Mufasa/Circle%20of%20Life/SHA-1", "", http.StatusBadRequest},
+	}
+
+	s := &appServer{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if len(tt.authorization) > 0 {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			s.handleDigestAuth(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+
+			if tt.status == http.StatusUnauthorized && !strings.HasPrefix(rec.Header().Get("WWW-Authenticate"), "Digest ") {
+				t.Errorf("WWW-Authenticate = %q", rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestParseAuthParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  map[string]string
+	}{
+		{"empty", "", map[string]string{}},
+		{"unquoted", "a=1, b=2", map[string]string{"a": "1", "b": "2"}},
+		{"quoted", `realm="a, b", nc=1`, map[string]string{"realm": "a, b", "nc": "1"}},
+		{"escaped quote", `user="a\"b", x=y`, map[string]string{"user": `a"b`, "x": "y"}},
+		{"key case and spaces", ` Username = "bob" ,QOP=auth`, map[string]string{"username": "bob", "qop": "auth"}},
+		{"empty value", `a="", b=`, map[string]string{"a": "", "b": ""}},
+		{"unterminated quote", `a="abc`, map[string]string{"a": "abc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseAuthParams(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAuthParams(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// handleCache returns 304 Not Modified if the request is conditional
+// (If-None-Match or If-Modified-Since), otherwise the request echo with
+// ETag and Last-Modified headers.
+func (s *appServer) handleCache(w http.ResponseWriter, req *http.Request) {
+	if len(req.Header.Get("If-None-Match")) > 0 || len(req.Header.Get("If-Modified-Since")) > 0 {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	etag, err := newUUID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("ETag", `"`+etag+`"`)
+	w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+
+	s.handleEcho(w, req)
+}
+
+// handleCacheControl returns the request echo with a Cache-Control header
+// allowing to cache the response for the number of seconds given in the path.
+func (s *appServer) handleCacheControl(w http.ResponseWriter, req *http.Request) {
+	seconds, err := strconv.Atoi(pathParam(req, "/cache/"))
+	if err != nil || seconds < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid number of seconds: %s", pathParam(req, "/cache/")))
+		return
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", seconds))
+
+	s.handleEcho(w, req)
+}
@@ -1,20 +1,154 @@
 package cmd
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"net"
 	"os"
+	"slices"
 	"time"
 )
 
+type certOptions struct {
+	commonName string        // Subject common name
+	hosts      []string      // DNS names and IP addresses
+	keyType    string        // Key type (rsa, ecdsa, ed25519)
+	validity   time.Duration // Validity period
+	isCA       bool          // Certificate authority
+	client     bool          // Client authentication certificate
+}
+
+// generateCert generates the server certificate used when no certificate
+// file is specified. The certificate is signed by the local CA if it is
+// configured, otherwise it is self-signed.
 func generateCert() (certFilePath string, keyFilePath string, err error) {
-	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	opts := certOptions{
+		commonName: "Echo Server",
+		hosts:      certHosts(),
+		keyType:    config.https.certKeyType,
+		validity:   config.https.certValidity,
+	}
+
+	var parent *x509.Certificate
+	var parentKey crypto.Signer
+	if len(config.https.caCert) > 0 || len(config.https.caKey) > 0 {
+		if parent, parentKey, err = loadOrCreateCA(config.https.caCert, config.https.caKey); err != nil {
+			return
+		}
+	}
+
+	derBytes, priv, err := createCertificate(opts, parent, parentKey)
 	if err != nil {
-		err = fmt.Errorf("failed to generate private key: %v", err)
+		return
+	}
+
+	if certFilePath, err = createCert(derBytes); err != nil {
+		return
+	}
+
+	if keyFilePath, err = createKey(priv); err != nil {
+		os.Remove(certFilePath)
+		certFilePath = ""
+		return
+	}
+
+	return
+}
+
+// certHosts returns the host names of the generated server certificate.
+func certHosts() []string {
+	hosts := []string{}
+	seen := make(map[string]bool)
+
+	candidates := slices.Concat(config.server.hosts, []string{"localhost", "127.0.0.1", "::1"}, config.https.certHosts)
+	for _, host := range candidates {
+		if len(host) == 0 || seen[host] {
+			continue
+		}
+
+		if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+			continue
+		}
+
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+
+	return hosts
+}
+
+// loadOrCreateCA loads the CA certificate and key, creating and saving them
+// if neither file exists yet.
+func loadOrCreateCA(certFile string, keyFile string) (*x509.Certificate, crypto.Signer, error) {
+	if len(certFile) == 0 || len(keyFile) == 0 {
+		return nil, nil, errors.New("both CA certificate and CA key file must be specified")
+	}
+
+	if fileExists(certFile) || fileExists(keyFile) {
+		return loadCA(certFile, keyFile)
+	}
+
+	opts := certOptions{
+		commonName: "Echo Server Local CA",
+		keyType:    config.https.certKeyType,
+		validity:   10 * 365 * 24 * time.Hour,
+		isCA:       true,
+	}
+
+	derBytes, priv, err := createCertificate(opts, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := writeCertFile(certFile, derBytes); err != nil {
+		return nil, nil, err
+	}
+
+	if err := writeKeyFile(keyFile, priv); err != nil {
+		return nil, nil, err
+	}
+
+	cert, err := x509.ParseCertificate(derBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cert, priv, nil
+}
+
+func loadCA(certFile string, keyFile string) (*x509.Certificate, crypto.Signer, error) {
+	certs, err := readCertFile(certFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	priv, err := readKeyFile(keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !certs[0].IsCA {
+		return nil, nil, fmt.Errorf("certificate is not a CA: %s", certFile)
+	}
+
+	return certs[0], priv, nil
+}
+
+// createCertificate creates a certificate and its private key. The
+// certificate is signed by parent, or self-signed if parent is nil.
+func createCertificate(opts certOptions, parent *x509.Certificate, parentKey crypto.Signer) (derBytes []byte, priv crypto.Signer, err error) {
+	if priv, err = generateKey(opts.keyType); err != nil {
 		return
 	}
 
@@ -26,74 +160,210 @@ func generateCert() (certFilePath string, keyFilePath string, err error) {
 
 	template := x509.Certificate{
 		SerialNumber:          serialNumber,
-		NotBefore:             time.Now(),
-		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		Subject:               pkix.Name{CommonName: opts.commonName, Organization: []string{"Echo Server"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(opts.validity),
 		KeyUsage:              x509.KeyUsageDigitalSignature,
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		BasicConstraintsValid: true,
 	}
 
-	if certFilePath, err = createCert(&template, priv); err != nil {
-		return
+	if _, isRSA := priv.(*rsa.PrivateKey); isRSA {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
 	}
 
-	if keyFilePath, err = createKey(priv); err != nil {
-		return
+	if opts.isCA {
+		template.IsCA = true
+		template.KeyUsage |= x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	} else if opts.client {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	} else {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+
+	for _, host := range opts.hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	if parent == nil {
+		parent, parentKey = &template, priv
+	}
+
+	if derBytes, err = x509.CreateCertificate(rand.Reader, &template, parent, priv.Public(), parentKey); err != nil {
+		err = fmt.Errorf("failed to create certificate: %v", err)
 	}
 
 	return
 }
 
-func createCert(template *x509.Certificate, priv *rsa.PrivateKey) (certFilePath string, err error) {
-	certOut, err := os.CreateTemp(os.TempDir(), "server_crt_")
+func generateKey(keyType string) (priv crypto.Signer, err error) {
+	switch keyType {
+	case "rsa":
+		priv, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ecdsa":
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ed25519":
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("invalid key type: %s", keyType)
+	}
+
 	if err != nil {
-		return
+		err = fmt.Errorf("failed to generate private key: %v", err)
 	}
 
-	derBytes, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	return
+}
+
+func createCert(derBytes []byte) (certFilePath string, err error) {
+	return createTempFile("server_crt_", func(w io.Writer) error {
+		return encodeCert(w, derBytes)
+	})
+}
+
+func createKey(priv crypto.Signer) (keyFilePath string, err error) {
+	return createTempFile("server_key_", func(w io.Writer) error {
+		return encodeKey(w, priv)
+	})
+}
+
+// createTempFile creates a temporary file with the content written by write.
+// The file is removed if it cannot be written.
+func createTempFile(pattern string, write func(w io.Writer) error) (string, error) {
+	out, err := os.CreateTemp(os.TempDir(), pattern)
 	if err != nil {
-		err = fmt.Errorf("failed to create certificate: %v", err)
-		return
+		return "", err
 	}
 
-	if err = pem.Encode(certOut, &pem.Block{Type: "CERTIFICATE", Bytes: derBytes}); err != nil {
-		err = fmt.Errorf("failed to write data to %s: %v", certOut.Name(), err)
-		return
+	if err := write(out); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return "", err
 	}
 
-	if err = certOut.Close(); err != nil {
-		err = fmt.Errorf("error closing %s: %v", certOut.Name(), err)
-		return
+	if err := out.Close(); err != nil {
+		os.Remove(out.Name())
+		return "", fmt.Errorf("error closing %s: %v", out.Name(), err)
 	}
 
-	certFilePath = certOut.Name()
+	return out.Name(), nil
+}
 
-	return
+func writeCertFile(path string, derBytes []byte) error {
+	certOut, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if err = encodeCert(certOut, derBytes); err != nil {
+		certOut.Close()
+		return err
+	}
+
+	return certOut.Close()
 }
 
-func createKey(priv *rsa.PrivateKey) (keyFilePath string, err error) {
-	keyOut, err := os.CreateTemp(os.TempDir(), "server_key_")
+func writeKeyFile(path string, priv crypto.Signer) error {
+	keyOut, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
-		return
+		return err
+	}
+
+	if err = encodeKey(keyOut, priv); err != nil {
+		keyOut.Close()
+		return err
 	}
 
+	return keyOut.Close()
+}
+
+func encodeCert(w io.Writer, derBytes []byte) error {
+	if err := pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: derBytes}); err != nil {
+		return fmt.Errorf("failed to write certificate: %v", err)
+	}
+
+	return nil
+}
+
+func encodeKey(w io.Writer, priv crypto.Signer) error {
 	privBytes, err := x509.MarshalPKCS8PrivateKey(priv)
 	if err != nil {
-		err = fmt.Errorf("unable to marshal private key: %v", err)
-		return
+		return fmt.Errorf("unable to marshal private key: %v", err)
 	}
 
-	if err = pem.Encode(keyOut, &pem.Block{Type: "PRIVATE KEY", Bytes: privBytes}); err != nil {
-		err = fmt.Errorf("failed to write data to %s: %v", keyOut.Name(), err)
-		return
+	if err := pem.Encode(w, &pem.Block{Type: "PRIVATE KEY", Bytes: privBytes}); err != nil {
+		return fmt.Errorf("failed to write private key: %v", err)
 	}
 
-	if err = keyOut.Close(); err != nil {
-		err = fmt.Errorf("error closing %s: %v", keyOut.Name(), err)
-		return
+	return nil
+}
+
+// readCertFile reads every certificate of a PEM file.
+func readCertFile(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
 
-	keyFilePath = keyOut.Name()
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			break
+		}
 
-	return
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate in %s: %v", path, err)
+		}
+
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+
+	return certs, nil
+}
+
+// readKeyFile reads a PKCS #8, PKCS #1 or SEC 1 encoded private key.
+func readKeyFile(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no private key found in %s", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key in %s: %v", path, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type in %s", path)
+	}
+
+	return signer, nil
 }
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+func newCertCommand() *cli.Command {
+	return &cli.Command{
+		Name:            "cert",
+		Usage:           "Generate and inspect certificates",
+		HideHelpCommand: true,
+		Subcommands: []*cli.Command{
+			{
+				Name:      "generate",
+				Usage:     "Generate a CA, server or client certificate and private key",
+				UsageText: fmt.Sprintf("%s cert generate [options]", app.name),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "type",
+						Value: "server",
+						Usage: "Certificate `type` (ca, server, client)",
+					},
+					&cli.StringFlag{
+						Name:  "out",
+						Value: ".",
+						Usage: "Output `directory`",
+					},
+					&cli.StringFlag{
+						Name:  "name",
+						Usage: "Output file `name` without extension (default: type)",
+					},
+					&cli.StringFlag{
+						Name:  "cn",
+						Usage: "Subject common `name`",
+					},
+					&cli.StringSliceFlag{
+						Name:  "host",
+						Usage: "DNS name or IP address of the certificate (can be repeated)",
+					},
+					&cli.StringFlag{
+						Name:  "key-type",
+						Value: "rsa",
+						Usage: "Key `type` (rsa, ecdsa, ed25519)",
+					},
+					&cli.DurationFlag{
+						Name:  "validity",
+						Value: 365 * 24 * time.Hour,
+						Usage: "Validity of the certificate",
+					},
+					&cli.StringFlag{
+						Name:  "ca-crt-file",
+						Usage: "Location of the CA certificate `file` signing the certificate (default: self-signed)",
+					},
+					&cli.StringFlag{
+						Name:  "ca-key-file",
+						Usage: "Location of the CA private key `file`",
+					},
+				},
+				Action: generateCertCommand,
+			},
+			{
+				Name:      "inspect",
+				Usage:     "Print the details of the certificates in PEM files",
+				UsageText: fmt.Sprintf("%s cert inspect file...", app.name),
+				Action:    inspectCertCommand,
+			},
+		},
+	}
+}
+
+func generateCertCommand(cCtx *cli.Context) error {
+	certType := cCtx.String("type")
+
+	opts := certOptions{
+		commonName: cCtx.String("cn"),
+		hosts:      cCtx.StringSlice("host"),
+		keyType:    cCtx.String("key-type"),
+		validity:   cCtx.Duration("validity"),
+	}
+
+	switch certType {
+	case "ca":
+		opts.isCA = true
+		if len(opts.commonName) == 0 {
+			opts.commonName = "Echo Server Local CA"
+		}
+	case "server":
+		if len(opts.hosts) == 0 {
+			opts.hosts = []string{"localhost", "127.0.0.1", "::1"}
+		}
+		if len(opts.commonName) == 0 {
+			opts.commonName = opts.hosts[0]
+		}
+	case "client":
+		opts.client = true
+		if len(opts.commonName) == 0 {
+			opts.commonName = "client"
+		}
+	default:
+		return fmt.Errorf("invalid certificate type: %s", certType)
+	}
+
+	if opts.validity <= 0 {
+		return fmt.Errorf("invalid certificate validity: %v", opts.validity)
+	}
+
+	var parent *x509.Certificate
+	var parentKey crypto.Signer
+	if len(cCtx.String("ca-crt-file")) > 0 || len(cCtx.String("ca-key-file")) > 0 {
+		if len(cCtx.String("ca-crt-file")) == 0 || len(cCtx.String("ca-key-file")) == 0 {
+			return errors.New("both CA certificate and CA key file must be specified")
+		}
+
+		var err error
+		if parent, parentKey, err = loadCA(cCtx.String("ca-crt-file"), cCtx.String("ca-key-file")); err != nil {
+			return err
+		}
+	}
+
+	derBytes, priv, err := createCertificate(opts, parent, parentKey)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(cCtx.String("out"), 0750); err != nil {
+		return err
+	}
+
+	name := cCtx.String("name")
+	if len(name) == 0 {
+		name = certType
+	}
+
+	certFile := filepath.Join(cCtx.String("out"), name+".crt")
+	keyFile := filepath.Join(cCtx.String("out"), name+".key")
+
+	if err := writeCertFile(certFile, derBytes); err != nil {
+		return err
+	}
+
+	if err := writeKeyFile(keyFile, priv); err != nil {
+		return err
+	}
+
+	fmt.Printf("Certificate: %s\nPrivate key: %s\n", certFile, keyFile)
+
+	return nil
+}
+
+func inspectCertCommand(cCtx *cli.Context) error {
+	if cCtx.NArg() == 0 {
+		return errors.New("certificate file must be specified")
+	}
+
+	for i, path := range cCtx.Args().Slice() {
+		certs, err := readCertFile(path)
+		if err != nil {
+			return err
+		}
+
+		for j, cert := range certs {
+			if i > 0 || j > 0 {
+				fmt.Println()
+			}
+
+			printCertInfo(path, newCertInfo(cert))
+		}
+	}
+
+	return nil
+}
+
+func printCertInfo(path string, info certInfo) {
+	printCertField("File", path)
+	printCertField("Subject", info.Subject)
+	printCertField("Issuer", info.Issuer)
+	printCertField("Serial number", info.SerialNumber)
+	printCertField("CA", fmt.Sprint(info.IsCA))
+	printCertField("Not before", info.NotBefore.Format(time.RFC3339))
+	printCertField("Not after", info.NotAfter.Format(time.RFC3339))
+	printCertField("DNS names", strings.Join(info.DNSNames, ", "))
+	printCertField("IP addresses", strings.Join(info.IPAddresses, ", "))
+	printCertField("Email addresses", strings.Join(info.EmailAddresses, ", "))
+	printCertField("URIs", strings.Join(info.URIs, ", "))
+	printCertField("SHA-1 fingerprint", info.SHA1Fingerprint)
+	printCertField("SHA-256 fingerprint", info.SHA256Fingerprint)
+}
+
+func printCertField(name string, value string) {
+	if len(value) > 0 {
+		fmt.Printf("%-21s%s\n", name+":", value)
+	}
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type certEntry struct {
+	CertFile string   `yaml:"crt-file"` // SSL certificate file
+	KeyFile  string   `yaml:"key-file"` // Private key file
+	Hosts    []string `yaml:"hosts"`    // Host names or wildcard patterns (e.g. *.example.com)
+}
+
+// certStore selects the certificate of the HTTPS server based on the SNI
+// server name of the client. The certificates can be reloaded while the
+// server is running.
+type certStore struct {
+	mu       sync.RWMutex
+	def      *tls.Certificate   // Certificate used if no host name matches
+	certs    []*tls.Certificate // Certificates of config.https.certificates
+	modTimes map[string]time.Time
+}
+
+// load loads the certificates and swaps them atomically. On error the
+// previously loaded certificates are kept.
+func (cs *certStore) load() error {
+	def, err := loadCertificate(config.https.cert, config.https.key)
+	if err != nil {
+		return err
+	}
+
+	certs := make([]*tls.Certificate, len(config.https.certificates))
+	for i, entry := range config.https.certificates {
+		if len(entry.CertFile) == 0 || len(entry.KeyFile) == 0 {
+			return fmt.Errorf("certificate and key file must be specified for hosts: %s", strings.Join(entry.Hosts, ", "))
+		}
+
+		if len(entry.Hosts) == 0 {
+			return fmt.Errorf("hosts must be specified for certificate: %s", entry.CertFile)
+		}
+
+		if certs[i], err = loadCertificate(entry.CertFile, entry.KeyFile); err != nil {
+			return err
+		}
+	}
+
+	modTimes := cs.fileModTimes()
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.def = def
+	cs.certs = certs
+	cs.modTimes = modTimes
+
+	return nil
+}
+
+// reload reloads the certificates and logs the new ones.
+func (cs *certStore) reload() {
+	if err := cs.load(); err != nil {
+		log.error.Printf("certificate reload error: %v\n", err)
+		return
+	}
+
+	cs.logCertificates()
+}
+
+func (cs *certStore) logCertificates() {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	logCertificate(config.https.cert, cs.def)
+	for i, entry := range config.https.certificates {
+		logCertificate(entry.CertFile, cs.certs[i])
+	}
+}
+
+// watch reloads the certificates whenever one of the files changes, until
+// done is closed.
+func (cs *certStore) watch(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		modTimes := cs.fileModTimes()
+
+		cs.mu.RLock()
+		changed := false
+		for path, modTime := range modTimes {
+			if !modTime.Equal(cs.modTimes[path]) {
+				changed = true
+			}
+		}
+		cs.mu.RUnlock()
+
+		if changed {
+			log.info.Println("certificate files changed, reloading")
+			cs.reload()
+		}
+	}
+}
+
+func (cs *certStore) fileModTimes() map[string]time.Time {
+	files := []string{config.https.cert, config.https.key}
+	for _, entry := range config.https.certificates {
+		files = append(files, entry.CertFile, entry.KeyFile)
+	}
+
+	modTimes := make(map[string]time.Time)
+	for _, path := range files {
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime()
+		}
+	}
+
+	return modTimes
+}
+
+// getCertificate implements tls.Config.GetCertificate. Exact host names take
+// precedence over wildcard patterns.
+func (cs *certStore) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	serverName := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+	if len(serverName) == 0 {
+		return cs.def, nil
+	}
+
+	for i, entry := range config.https.certificates {
+		for _, host := range entry.Hosts {
+			if host == serverName {
+				return cs.certs[i], nil
+			}
+		}
+	}
+
+	for i, entry := range config.https.certificates {
+		for _, host := range entry.Hosts {
+			if matchWildcard(host, serverName) {
+				return cs.certs[i], nil
+			}
+		}
+	}
+
+	return cs.def, nil
+}
+
+func loadCertificate(certFile string, keyFile string) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not load SSL certificate %s: %v", certFile, err)
+	}
+
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return nil, fmt.Errorf("could not parse SSL certificate %s: %v", certFile, err)
+	}
+
+	return &cert, nil
+}
+
+func logCertificate(path string, cert *tls.Certificate) {
+	log.info.Printf("certificate loaded: %s (subject: %s, SHA-256: %s, expires: %s)\n",
+		path, cert.Leaf.Subject, fingerprint(sha256Sum(cert.Leaf.Raw)), cert.Leaf.NotAfter.Format(time.RFC3339))
+}
+
+// matchWildcard reports whether name matches a wildcard pattern, where the
+// wildcard matches exactly one label.
+func matchWildcard(pattern string, name string) bool {
+	if !strings.HasPrefix(pattern, "*.") {
+		return false
+	}
+
+	label, rest, ok := strings.Cut(name, ".")
+
+	return ok && len(label) > 0 && rest == pattern[2:]
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestMatchWildcard(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.example.com", "www.example.com", true},
+		{"*.example.com", "api.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", "a.b.example.com", false},
+		{"*.example.com", ".example.com", false},
+		{"*.example.com", "www.example.org", false},
+		{"*.example.com", "wwwexample.com", false},
+		{"www.example.com", "www.example.com", false},
+		{"*example.com", "www.example.com", false},
+		{"*.*.example.com", "a.b.example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			if got := matchWildcard(tt.pattern, tt.name); got != tt.want {
+				t.Errorf("matchWildcard(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCertificate(t *testing.T) {
+	certificates := config.https.certificates
+	t.Cleanup(func() { config.https.certificates = certificates })
+
+	config.https.certificates = []*certEntry{
+		{Hosts: []string{"*.example.com"}},
+		{Hosts: []string{"www.example.com", "example.org"}},
+	}
+
+	def, wildcard, exact := &tls.Certificate{}, &tls.Certificate{}, &tls.Certificate{}
+	cs := &certStore{def: def, certs: []*tls.Certificate{wildcard, exact}}
+
+	tests := []struct {
+		serverName string
+		want       *tls.Certificate
+	}{
+		{"", def},
+		{"api.example.com", wildcard},
+		{"www.example.com", exact},
+		{"WWW.Example.COM.", exact},
+		{"example.org", exact},
+		{"example.com", def},
+		{"a.b.example.com", def},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serverName, func(t *testing.T) {
+			got, err := cs.getCertificate(&tls.ClientHelloInfo{ServerName: tt.serverName})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("getCertificate(%q) returned the wrong certificate", tt.serverName)
+			}
+		})
+	}
+}
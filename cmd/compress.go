@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Supported content codings in order of preference.
+var encodings = []string{"br", "gzip", "deflate"}
+
+func newEncoder(encoding string, w io.Writer) (io.WriteCloser, error) {
+	switch encoding {
+	case "br":
+		return brotli.NewWriter(w), nil
+	case "gzip":
+		return gzip.NewWriter(w), nil
+	case "deflate":
+		return zlib.NewWriter(w), nil
+	}
+
+	return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+}
+
+func newDecoder(encoding string, r io.Reader) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return r, nil
+	case "br":
+		return brotli.NewReader(r), nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		return zlib.NewReader(r)
+	}
+
+	return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+}
+
+// readBody reads the request body, decoding it according to the
+// Content-Encoding header.
+func readBody(req *http.Request) ([]byte, error) {
+	var body io.Reader = req.Body
+
+	codings := strings.Split(req.Header.Get("Content-Encoding"), ",")
+	for i := len(codings) - 1; i >= 0; i-- {
+		var err error
+		if body, err = newDecoder(codings[i], body); err != nil {
+			return nil, fmt.Errorf("could not decode request body: %v", err)
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("could not read request body: %v", err)
+	}
+
+	return data, nil
+}
+
+// negotiateEncoding returns the preferred supported content coding of an
+// Accept-Encoding header, or an empty string if none of them is acceptable.
+func negotiateEncoding(acceptEncoding string) string {
+	best, bestQ := "", 0.0
+
+	for _, encoding := range encodings {
+		if q := encodingQuality(acceptEncoding, encoding); q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+
+	return best
+}
+
+func encodingQuality(acceptEncoding string, encoding string) float64 {
+	quality := 0.0
+
+	for _, item := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != encoding && name != "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if key, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && key == "q" {
+				if v, err := strconv.ParseFloat(value, 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		// An explicit entry takes precedence over the wildcard.
+		if name == encoding {
+			return q
+		}
+
+		quality = q
+	}
+
+	return quality
+}
+
+// writeCompressed writes data compressed with the given content coding.
+func writeCompressed(w http.ResponseWriter, status int, encoding string, data []byte) {
+	var buf bytes.Buffer
+
+	encoder, err := newEncoder(encoding, &buf)
+	if err == nil {
+		if _, err = encoder.Write(data); err == nil {
+			err = encoder.Close()
+		}
+	}
+
+	if err != nil {
+		log.error.Println("could not compress response:", err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Encoding", encoding)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Header().Add("Vary", "Accept-Encoding")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// handleCompressed returns a handler responding with the request echo
+// compressed with the given content coding.
+func (s *appServer) handleCompressed(encoding string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		echo, err := newRequestEcho(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		data, err := marshalJSON(echo)
+		if err != nil {
+			log.error.Println("could not marshal response:", err)
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		writeCompressed(w, http.StatusOK, encoding, data)
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"GZIP", "gzip"},
+		{"gzip, deflate, br", "br"},
+		{"deflate, gzip", "gzip"},
+		{"br;q=0.5, gzip;q=0.8", "gzip"},
+		{"br; q=0.5, deflate; q=1", "deflate"},
+		{"*", "br"},
+		{"*;q=0.5, br;q=0.1", "gzip"},
+		{"*, br;q=0", "gzip"},
+		{"br;q=0, gzip;q=0, deflate;q=0", ""},
+		{"*;q=0", ""},
+		{"gzip;q=invalid", "gzip"},
+		{"compress, zstd", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding, func(t *testing.T) {
+			if got := negotiateEncoding(tt.acceptEncoding); got != tt.want {
+				t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadBodyEncoded(t *testing.T) {
+	data := []byte(strings.Repeat("echo ", 100))
+
+	for _, encoding := range encodings {
+		t.Run(encoding, func(t *testing.T) {
+			var b bytes.Buffer
+			enc, err := newEncoder(encoding, &b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			enc.Write(data)
+			enc.Close()
+
+			req := httptest.NewRequest("POST", "/", io.NopCloser(&b))
+			req.Header.Set("Content-Encoding", encoding)
+
+			body, err := readBody(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(body, data) {
+				t.Errorf("readBody() = %q, want %q", body, data)
+			}
+		})
+	}
+}
@@ -1,43 +1,94 @@
 package cmd
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
-	"net"
 	"os"
 	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 type configuration struct {
 	server struct {
-		host string
+		hosts     []string // Host names or IP addresses the servers bind to
+		ipVersion string   // IP version of the servers (4 or 6), both if empty
 	}
 
 	udp struct {
-		enabled bool        // UDP echo enabled
-		port    int         // UDP echo port
-		address net.UDPAddr // UDP echo address
+		enabled bool   // UDP echo enabled
+		ports   string // UDP echo ports and port ranges
 	}
 
 	tcp struct {
-		enabled bool        // TCP echo enabled
-		port    int         // TCP echo port
-		address net.TCPAddr // TCP echo address
+		enabled bool   // TCP echo enabled
+		ports   string // TCP echo ports and port ranges
+	}
+
+	tlsTCP struct {
+		enabled bool // TLS TCP echo enabled
+		port    int  // TLS TCP echo port
 	}
 
 	http struct {
-		enabled bool   // HTTP server enabled
-		port    int    // HTTP server port
-		address string // HTTP server address
+		enabled bool // HTTP server enabled
+		port    int  // HTTP server port
+		h2c     bool // Cleartext HTTP/2 enabled
 	}
 
 	https struct {
 		enabled  bool   // HTTPS server enabled
 		port     int    // HTTPS server port
-		address  string // HTTPS server address
+		http2    bool   // HTTP/2 enabled
 		cert     string // SSL certificate file
 		key      string // RSA private key file
 		autoCert bool   // Automatically generate SSL certificate
+
+		certHosts    []string      // Additional host names of the generated certificate
+		certKeyType  string        // Key type of the generated certificate
+		certValidity time.Duration // Validity of the generated certificate
+		caCert       string        // Local CA certificate file
+		caKey        string        // Local CA private key file
+
+		clientCAFile string // CA certificates file to verify client certificates
+		clientAuth   string // Client certificate authentication mode
+
+		certificates []*certEntry // Certificates selected by SNI server name
+		certs        certStore    // Loaded certificates
+
+		reloadInterval time.Duration // Interval of checking the certificate files for changes
+
+		tlsConfig *tls.Config // TLS configuration of the TLS based servers
+	}
+
+	grpc struct {
+		enabled bool // gRPC server enabled
+		port    int  // gRPC server port
+		tls     bool // Serve gRPC over TLS
+	}
+
+	http3 struct {
+		enabled      bool          // HTTP/3 server enabled
+		port         int           // HTTP/3 server UDP port
+		altSvcMaxAge time.Duration // Max. age of the Alt-Svc header
+	}
+
+	http2 struct {
+		maxConcurrentStreams int // Maximum number of concurrent streams per connection
+	}
+
+	tls struct {
+		preset         string   // TLS policy preset (modern, intermediate, legacy)
+		minVersion     string   // Minimum TLS version
+		maxVersion     string   // Maximum TLS version
+		ciphers        []string // Allowed TLS 1.0-1.2 cipher suites
+		curves         []string // Curve preferences
+		alpn           []string // ALPN protocols
+		sessionTickets bool     // Session tickets enabled
 	}
 
 	content struct {
@@ -45,6 +96,20 @@ type configuration struct {
 		file           string // Path to file which contains response body
 		contentType    string // Content-Type header
 		addContentType bool   // Add Content-Type header
+		isTemplate     bool   // Render response body as a Go template
+		compress       bool   // Compress response body based on Accept-Encoding
+
+		template *template.Template // Parsed response body template
+	}
+
+	ws struct {
+		subprotocols []string // Supported WebSocket subprotocols
+		compression  bool     // Negotiate permessage-deflate compression
+	}
+
+	sse struct {
+		interval time.Duration // Interval between SSE events
+		retry    int           // SSE reconnection time in milliseconds
 	}
 
 	log struct {
@@ -55,66 +120,67 @@ type configuration struct {
 		packets     bool   // Log incoming/outgoing packets
 	}
 
-	quiet bool // Quiet mode enabled
+	listeners []*listenerConfig // Servers started on the configured ports
+	routes    []*route          // Configured HTTP(S) routes
+
+	file  string // Configuration file
+	quiet bool   // Quiet mode enabled
 }
 
-func (c *configuration) init() error {
-	if !c.http.enabled && !c.https.enabled && !c.udp.enabled && !c.tcp.enabled {
-		return errors.New("one of the following options must be enabled: http, https, tcp echo, udp echo")
-	}
+// configFile contains the options of the configuration file which can not be
+// expressed as command line flags.
+type configFile struct {
+	Listeners    []*listenerConfig `yaml:"listeners"`
+	Routes       []*route          `yaml:"routes"`
+	Certificates []*certEntry      `yaml:"certificates"`
+}
 
-	if c.http.enabled {
-		if !isValidPort(c.http.port) {
-			return fmt.Errorf("invalid HTTP port number: %v", c.http.port)
+func (c *configuration) init() (err error) {
+	// The generated certificate is removed if a later step fails, as the
+	// server (which removes it on stop) is not started then.
+	defer func() {
+		if err != nil {
+			c.removeAutoCert()
 		}
+	}()
 
-		c.http.address = net.JoinHostPort(c.server.host, strconv.Itoa(c.http.port))
+	if len(c.file) > 0 {
+		if err := c.load(); err != nil {
+			return err
+		}
 	}
 
-	if c.https.enabled {
-		if !isValidPort(c.https.port) {
-			return fmt.Errorf("invalid HTTPS port number: %v", c.https.port)
-		}
+	if len(c.server.ipVersion) > 0 && c.server.ipVersion != "4" && c.server.ipVersion != "6" {
+		return fmt.Errorf("invalid IP version: %s", c.server.ipVersion)
+	}
 
-		c.https.address = net.JoinHostPort(c.server.host, strconv.Itoa(c.https.port))
+	c.listeners = append(c.flagListeners(), c.listeners...)
+	if len(c.listeners) == 0 {
+		return errors.New("one of the following options must be enabled: http, https, http3, grpc, tcp echo, tls tcp echo, udp echo (or listeners must be configured)")
+	}
 
-		if len(c.https.cert) == 0 && len(c.https.key) == 0 {
-			if cert, key, err := generateCert(); err != nil {
-				return err
-			} else {
-				c.https.autoCert = true
-				c.https.cert = cert
-				c.https.key = key
-			}
-		} else {
-			if len(c.https.cert) == 0 {
-				return errors.New("SSL certificate file must be specified")
-			} else if !fileExists(c.https.cert) {
-				return fmt.Errorf("SSL certificate file specified but not found: %s", c.https.cert)
-			}
+	for _, l := range c.listeners {
+		if len(l.Hosts) == 0 {
+			l.Hosts = c.server.hosts
+		}
 
-			if len(c.https.key) == 0 {
-				return errors.New("RSA private key file must be specified")
-			} else if !fileExists(c.https.key) {
-				return fmt.Errorf("RSA private key file specified but not found: %s", c.https.key)
-			}
+		if err := l.init(); err != nil {
+			return err
 		}
 	}
 
-	if c.tcp.enabled {
-		if !isValidPort(c.tcp.port) {
-			return fmt.Errorf("invalid TCP echo port number: %v", c.tcp.port)
-		}
+	if c.http3.altSvcMaxAge < 0 {
+		return fmt.Errorf("invalid Alt-Svc max. age: %v", c.http3.altSvcMaxAge)
+	}
 
-		c.tcp.address = net.TCPAddr{Port: c.tcp.port, IP: net.ParseIP(c.server.host)}
+	if c.http2.maxConcurrentStreams <= 0 {
+		return fmt.Errorf("invalid HTTP/2 max. concurrent streams: %v", c.http2.maxConcurrentStreams)
 	}
 
-	if c.udp.enabled {
-		if !isValidPort(c.udp.port) {
-			return fmt.Errorf("invalid UDP echo port number: %v", c.udp.port)
+	if c.tlsEnabled() {
+		if err := c.initTLS(); err != nil {
+			return err
 		}
-
-		c.udp.address = net.UDPAddr{Port: c.udp.port, IP: net.ParseIP(c.server.host)}
 	}
 
 	if len(c.content.file) > 0 {
@@ -134,5 +200,168 @@ func (c *configuration) init() error {
 		c.content.addContentType = true
 	}
 
+	if c.content.isTemplate {
+		var err error
+		if c.content.template, err = parseTemplate("content", c.content.content); err != nil {
+			return err
+		}
+	}
+
+	if c.sse.interval <= 0 {
+		return fmt.Errorf("invalid SSE interval: %v", c.sse.interval)
+	}
+
+	return nil
+}
+
+// flagListeners returns the listeners of the command line options.
+func (c *configuration) flagListeners() []*listenerConfig {
+	var listeners []*listenerConfig
+	tcp, udp := "tcp"+c.server.ipVersion, "udp"+c.server.ipVersion
+
+	if c.http.enabled {
+		listeners = append(listeners, &listenerConfig{Protocol: protoHTTP, Network: tcp, Port: strconv.Itoa(c.http.port), H2C: c.http.h2c})
+	}
+
+	if c.https.enabled {
+		listeners = append(listeners, &listenerConfig{Protocol: protoHTTPS, Network: tcp, Port: strconv.Itoa(c.https.port), HTTP2: &c.https.http2})
+	}
+
+	if c.http3.enabled {
+		port := c.http3.port
+		if port == 0 {
+			port = c.https.port
+		}
+
+		listeners = append(listeners, &listenerConfig{Protocol: protoHTTP3, Network: udp, Port: strconv.Itoa(port)})
+	}
+
+	if c.grpc.enabled {
+		listeners = append(listeners, &listenerConfig{Protocol: protoGRPC, Network: tcp, Port: strconv.Itoa(c.grpc.port), TLS: c.grpc.tls})
+	}
+
+	if c.tcp.enabled {
+		listeners = append(listeners, &listenerConfig{Protocol: protoTCP, Network: tcp, Port: c.tcp.ports})
+	}
+
+	if c.tlsTCP.enabled {
+		listeners = append(listeners, &listenerConfig{Protocol: protoTLSTCP, Network: tcp, Port: strconv.Itoa(c.tlsTCP.port)})
+	}
+
+	if c.udp.enabled {
+		listeners = append(listeners, &listenerConfig{Protocol: protoUDP, Network: udp, Port: c.udp.ports})
+	}
+
+	return listeners
+}
+
+// tlsEnabled reports whether any of the servers uses TLS.
+func (c *configuration) tlsEnabled() bool {
+	for _, l := range c.listeners {
+		if l.usesTLS() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// httpEnabled reports whether any of the servers serves HTTP.
+func (c *configuration) httpEnabled() bool {
+	for _, l := range c.listeners {
+		if l.isHTTP() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeAutoCert removes the files of the generated certificate.
+func (c *configuration) removeAutoCert() error {
+	if !c.https.autoCert {
+		return nil
+	}
+
+	c.https.autoCert = false
+
+	var errs []error
+	if err := os.Remove(c.https.cert); err != nil {
+		errs = append(errs, fmt.Errorf("error while removing cert file: %v", err))
+	}
+
+	if err := os.Remove(c.https.key); err != nil {
+		errs = append(errs, fmt.Errorf("error while removing key file: %v", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// initTLS loads or generates the certificates and creates the TLS
+// configuration shared by the HTTPS, HTTP/3, TLS TCP echo and gRPC servers.
+func (c *configuration) initTLS() error {
+	if len(c.https.cert) == 0 && len(c.https.key) == 0 {
+		if c.https.certValidity <= 0 {
+			return fmt.Errorf("invalid certificate validity: %v", c.https.certValidity)
+		}
+
+		if cert, key, err := generateCert(); err != nil {
+			return err
+		} else {
+			c.https.autoCert = true
+			c.https.cert = cert
+			c.https.key = key
+		}
+	} else {
+		if len(c.https.cert) == 0 {
+			return errors.New("SSL certificate file must be specified")
+		} else if !fileExists(c.https.cert) {
+			return fmt.Errorf("SSL certificate file specified but not found: %s", c.https.cert)
+		}
+
+		if len(c.https.key) == 0 {
+			return errors.New("RSA private key file must be specified")
+		} else if !fileExists(c.https.key) {
+			return fmt.Errorf("RSA private key file specified but not found: %s", c.https.key)
+		}
+	}
+
+	if err := c.https.certs.load(); err != nil {
+		return err
+	}
+
+	var err error
+	c.https.tlsConfig, err = newTLSConfig()
+
+	return err
+}
+
+func (c *configuration) load() error {
+	data, err := os.ReadFile(c.file)
+	if err != nil {
+		return err
+	}
+
+	var file configFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("could not parse configuration file %s: %v", c.file, err)
+	}
+
+	for _, r := range file.Routes {
+		if err := r.init(); err != nil {
+			return err
+		}
+	}
+
+	c.listeners = file.Listeners
+	c.routes = file.Routes
+	c.https.certificates = file.Certificates
+
+	for _, entry := range c.https.certificates {
+		for i, host := range entry.Hosts {
+			entry.Hosts[i] = strings.ToLower(host)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"net/http"
+	"time"
+)
+
+// handleCookies returns the cookies sent by the client.
+func (s *appServer) handleCookies(w http.ResponseWriter, req *http.Request) {
+	cookies := make(map[string]string)
+	for _, cookie := range req.Cookies() {
+		cookies[cookie.Name] = cookie.Value
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"cookies": cookies})
+}
+
+// handleSetCookies sets a cookie for every query parameter and redirects to
+// /cookies.
+func (s *appServer) handleSetCookies(w http.ResponseWriter, req *http.Request) {
+	for name, values := range req.URL.Query() {
+		http.SetCookie(w, &http.Cookie{Name: name, Value: values[len(values)-1], Path: "/"})
+	}
+
+	redirectTo(w, "/cookies", http.StatusFound)
+}
+
+// handleDeleteCookies expires the cookies named by the query parameters and
+// redirects to /cookies.
+func (s *appServer) handleDeleteCookies(w http.ResponseWriter, req *http.Request) {
+	for name := range req.URL.Query() {
+		http.SetCookie(w, &http.Cookie{Name: name, Path: "/", Expires: time.Unix(0, 0), MaxAge: -1})
+	}
+
+	redirectTo(w, "/cookies", http.StatusFound)
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxBodySize = 10 << 20 // Maximum size of a request body read by the echo handlers
+
+type requestEcho struct {
+	Method        string                `json:"method"`
+	URL           string                `json:"url"`
+	Path          string                `json:"path"`
+	Args          url.Values            `json:"args"`
+	Headers       http.Header           `json:"headers"`
+	RemoteAddr    string                `json:"remote_addr"`
+	Proto         string                `json:"proto"`
+	Host          string                `json:"host"`
+	TLS           *tlsInfo              `json:"tls"`
+	ContentLength int64                 `json:"content_length"`
+	Body          string                `json:"body"`
+	Form          url.Values            `json:"form"`
+	Files         map[string][]echoFile `json:"files"`
+	JSON          interface{}           `json:"json"`
+}
+
+type echoFile struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	Content     string `json:"content"`
+}
+
+func newRequestEcho(req *http.Request) (*requestEcho, error) {
+	echo := &requestEcho{
+		Method:        req.Method,
+		URL:           requestURL(req),
+		Path:          req.URL.Path,
+		Args:          req.URL.Query(),
+		Headers:       req.Header,
+		RemoteAddr:    req.RemoteAddr,
+		Proto:         req.Proto,
+		Host:          req.Host,
+		ContentLength: req.ContentLength,
+		TLS:           newTLSInfo(req),
+		Form:          url.Values{},
+		Files:         map[string][]echoFile{},
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	echo.Body = bodyString(body)
+
+	if len(body) == 0 {
+		return echo, nil
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		if echo.Form, err = url.ParseQuery(string(body)); err != nil {
+			return nil, fmt.Errorf("could not parse form body: %v", err)
+		}
+	case mediaType == "multipart/form-data":
+		if err = echo.parseMultipart(body, params["boundary"]); err != nil {
+			return nil, fmt.Errorf("could not parse multipart body: %v", err)
+		}
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		// Invalid JSON is not an error, the raw body is still echoed back.
+		if err := json.Unmarshal(body, &echo.JSON); err != nil {
+			echo.JSON = nil
+		}
+	}
+
+	return echo, nil
+}
+
+func (e *requestEcho) parseMultipart(body []byte, boundary string) error {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return err
+		}
+
+		if part.FileName() == "" {
+			e.Form.Add(part.FormName(), string(data))
+		} else {
+			e.Files[part.FormName()] = append(e.Files[part.FormName()], echoFile{
+				Filename:    part.FileName(),
+				ContentType: part.Header.Get("Content-Type"),
+				Size:        len(data),
+				Content:     bodyString(data),
+			})
+		}
+	}
+}
+
+func (s *appServer) handleEcho(w http.ResponseWriter, req *http.Request) {
+	echo, err := newRequestEcho(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, echo)
+}
+
+// requestURL reconstructs the absolute URL the client requested.
+func requestURL(req *http.Request) string {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + req.Host + req.URL.RequestURI()
+}
+
+// bodyString returns data as a string, or as a base64 data URL if it is not
+// valid UTF-8.
+func bodyString(data []byte) string {
+	if utf8.Valid(data) {
+		return string(data)
+	}
+
+	return "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+func marshalJSON(v interface{}) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, err := marshalJSON(v)
+	if err != nil {
+		log.error.Println("could not marshal response:", err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	data, _ := json.Marshal(map[string]string{"error": err.Error()})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
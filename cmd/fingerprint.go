@@ -0,0 +1,488 @@
+package cmd
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const maxClientHelloSize = 64 << 10 // Maximum captured size of the TLS records of a ClientHello
+
+// TLS extension types used by the fingerprints.
+const (
+	extServerName        uint16 = 0x0000
+	extSupportedGroups   uint16 = 0x000a
+	extPointFormats      uint16 = 0x000b
+	extSignatureAlgs     uint16 = 0x000d
+	extALPN              uint16 = 0x0010
+	extSupportedVersions uint16 = 0x002b
+)
+
+type clientFingerprint struct {
+	JA3     string `json:"ja3"`
+	JA3Hash string `json:"ja3_hash"`
+	JA4     string `json:"ja4"`
+}
+
+type clientHello struct {
+	version           uint16
+	ciphers           []uint16
+	extensions        []uint16
+	curves            []uint16
+	pointFormats      []uint8
+	signatureAlgs     []uint16
+	supportedVersions []uint16
+	alpn              []string
+	serverName        bool
+}
+
+// helloListener wraps the accepted connections into helloConn.
+type helloListener struct {
+	net.Listener
+}
+
+func (l *helloListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &helloConn{Conn: conn}, nil
+}
+
+// helloConn captures the TLS records of the ClientHello read from the
+// connection, and computes the fingerprints of the client once the
+// ClientHello is complete.
+type helloConn struct {
+	net.Conn
+	raw         []byte
+	done        bool
+	fingerprint *clientFingerprint
+}
+
+func (c *helloConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if !c.done && n > 0 {
+		c.capture(b[:n])
+	}
+
+	return n, err
+}
+
+func (c *helloConn) capture(data []byte) {
+	c.raw = append(c.raw, data...)
+
+	msg, complete, ok := handshakeMessage(c.raw)
+	if !ok || len(c.raw) > maxClientHelloSize {
+		c.done, c.raw = true, nil
+		return
+	}
+
+	if !complete {
+		return
+	}
+
+	if hello, ok := parseClientHello(msg); ok {
+		c.fingerprint = hello.fingerprint()
+	}
+
+	c.done, c.raw = true, nil
+}
+
+// handshakeMessage reassembles the first handshake message from the TLS
+// records. ok is false if the data is not a ClientHello.
+func handshakeMessage(data []byte) (msg []byte, complete bool, ok bool) {
+	for len(data) >= 5 {
+		if data[0] != 22 { // Handshake record
+			return nil, false, false
+		}
+
+		length := int(data[3])<<8 | int(data[4])
+		if len(data) < 5+length {
+			break
+		}
+
+		msg = append(msg, data[5:5+length]...)
+		data = data[5+length:]
+	}
+
+	if len(msg) < 4 {
+		return nil, false, true
+	}
+
+	if msg[0] != 1 { // ClientHello message
+		return nil, false, false
+	}
+
+	length := int(msg[1])<<16 | int(msg[2])<<8 | int(msg[3])
+	if len(msg) < 4+length {
+		return nil, false, true
+	}
+
+	return msg[4 : 4+length], true, true
+}
+
+func parseClientHello(data []byte) (*clientHello, bool) {
+	r := helloReader(data)
+	hello := &clientHello{}
+
+	var ok bool
+	if hello.version, ok = r.uint16(); !ok {
+		return nil, false
+	}
+
+	var sessionID, ciphers, compression helloReader
+	if !r.skip(32) || !r.vector8(&sessionID) || !r.vector16(&ciphers) || !r.vector8(&compression) {
+		return nil, false
+	}
+
+	if hello.ciphers, ok = ciphers.uint16s(); !ok {
+		return nil, false
+	}
+
+	if len(r) == 0 {
+		return hello, true
+	}
+
+	var extensions helloReader
+	if !r.vector16(&extensions) {
+		return nil, false
+	}
+
+	for len(extensions) > 0 {
+		var extType uint16
+		var extData helloReader
+		if extType, ok = extensions.uint16(); !ok || !extensions.vector16(&extData) {
+			return nil, false
+		}
+
+		hello.extensions = append(hello.extensions, extType)
+
+		switch extType {
+		case extServerName:
+			hello.serverName = true
+		case extSupportedGroups:
+			var groups helloReader
+			if !extData.vector16(&groups) {
+				return nil, false
+			}
+			if hello.curves, ok = groups.uint16s(); !ok {
+				return nil, false
+			}
+		case extPointFormats:
+			var formats helloReader
+			if !extData.vector8(&formats) {
+				return nil, false
+			}
+			hello.pointFormats = formats
+		case extSignatureAlgs:
+			var algs helloReader
+			if !extData.vector16(&algs) {
+				return nil, false
+			}
+			if hello.signatureAlgs, ok = algs.uint16s(); !ok {
+				return nil, false
+			}
+		case extSupportedVersions:
+			var versions helloReader
+			if !extData.vector8(&versions) {
+				return nil, false
+			}
+			if hello.supportedVersions, ok = versions.uint16s(); !ok {
+				return nil, false
+			}
+		case extALPN:
+			var protocols helloReader
+			if !extData.vector16(&protocols) {
+				return nil, false
+			}
+			for len(protocols) > 0 {
+				var protocol helloReader
+				if !protocols.vector8(&protocol) {
+					return nil, false
+				}
+				hello.alpn = append(hello.alpn, string(protocol))
+			}
+		}
+	}
+
+	return hello, true
+}
+
+func (h *clientHello) fingerprint() *clientFingerprint {
+	ja3 := h.ja3()
+	sum := md5.Sum([]byte(ja3))
+
+	return &clientFingerprint{
+		JA3:     ja3,
+		JA3Hash: hex.EncodeToString(sum[:]),
+		JA4:     h.ja4(),
+	}
+}
+
+// ja3 returns the JA3 string of the ClientHello: the decimal values of the
+// version, cipher suites, extensions, curves and point formats, without
+// GREASE values.
+func (h *clientHello) ja3() string {
+	points := make([]uint16, len(h.pointFormats))
+	for i, format := range h.pointFormats {
+		points[i] = uint16(format)
+	}
+
+	fields := []string{
+		strconv.Itoa(int(h.version)),
+		joinUint16s(withoutGREASE(h.ciphers), "-", "%d"),
+		joinUint16s(withoutGREASE(h.extensions), "-", "%d"),
+		joinUint16s(withoutGREASE(h.curves), "-", "%d"),
+		joinUint16s(points, "-", "%d"),
+	}
+
+	return strings.Join(fields, ",")
+}
+
+// ja4 returns the JA4 fingerprint of the ClientHello.
+func (h *clientHello) ja4() string {
+	version := h.version
+	if versions := withoutGREASE(h.supportedVersions); len(versions) > 0 {
+		version = 0
+		for _, v := range versions {
+			if v > version {
+				version = v
+			}
+		}
+	}
+
+	sni := "i"
+	if h.serverName {
+		sni = "d"
+	}
+
+	ciphers := withoutGREASE(h.ciphers)
+	extensions := withoutGREASE(h.extensions)
+
+	alpn := "00"
+	if len(h.alpn) > 0 && len(h.alpn[0]) > 0 {
+		protocol := h.alpn[0]
+		if isAlphanumeric(protocol[0]) && isAlphanumeric(protocol[len(protocol)-1]) {
+			alpn = string(protocol[0]) + string(protocol[len(protocol)-1])
+		} else {
+			encoded := hex.EncodeToString([]byte(protocol))
+			alpn = string(encoded[0]) + string(encoded[len(encoded)-1])
+		}
+	}
+
+	a := fmt.Sprintf("t%s%s%02d%02d%s", ja4Version(version), sni, clamp99(len(ciphers)), clamp99(len(extensions)), alpn)
+
+	sortedCiphers := append([]uint16{}, ciphers...)
+	sort.Slice(sortedCiphers, func(i, j int) bool { return sortedCiphers[i] < sortedCiphers[j] })
+
+	var sortedExtensions []uint16
+	for _, ext := range extensions {
+		if ext != extServerName && ext != extALPN {
+			sortedExtensions = append(sortedExtensions, ext)
+		}
+	}
+	sort.Slice(sortedExtensions, func(i, j int) bool { return sortedExtensions[i] < sortedExtensions[j] })
+
+	c := joinUint16s(sortedExtensions, ",", "%04x")
+	if algs := withoutGREASE(h.signatureAlgs); len(algs) > 0 {
+		c += "_" + joinUint16s(algs, ",", "%04x")
+	}
+
+	return fmt.Sprintf("%s_%s_%s", a, truncatedHash(sortedCiphers, joinUint16s(sortedCiphers, ",", "%04x")), truncatedHash(sortedExtensions, c))
+}
+
+func ja4Version(version uint16) string {
+	switch version {
+	case tls.VersionTLS13:
+		return "13"
+	case tls.VersionTLS12:
+		return "12"
+	case tls.VersionTLS11:
+		return "11"
+	case tls.VersionTLS10:
+		return "10"
+	case 0x0300:
+		return "s3"
+	}
+
+	return "00"
+}
+
+// truncatedHash returns the first 12 characters of the hex encoded SHA-256
+// hash of s, or zeros if values is empty.
+func truncatedHash(values []uint16, s string) string {
+	if len(values) == 0 {
+		return "000000000000"
+	}
+
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// isGREASE reports whether v is a GREASE value (RFC 8701).
+func isGREASE(v uint16) bool {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
+
+func withoutGREASE(values []uint16) []uint16 {
+	var filtered []uint16
+	for _, v := range values {
+		if !isGREASE(v) {
+			filtered = append(filtered, v)
+		}
+	}
+
+	return filtered
+}
+
+func joinUint16s(values []uint16, sep string, format string) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprintf(format, v)
+	}
+
+	return strings.Join(parts, sep)
+}
+
+func isAlphanumeric(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func clamp99(n int) int {
+	if n > 99 {
+		return 99
+	}
+
+	return n
+}
+
+// helloReader reads the fields of a ClientHello.
+type helloReader []byte
+
+func (r *helloReader) skip(n int) bool {
+	if len(*r) < n {
+		return false
+	}
+
+	*r = (*r)[n:]
+
+	return true
+}
+
+func (r *helloReader) uint16() (uint16, bool) {
+	if len(*r) < 2 {
+		return 0, false
+	}
+
+	v := uint16((*r)[0])<<8 | uint16((*r)[1])
+	*r = (*r)[2:]
+
+	return v, true
+}
+
+func (r *helloReader) vector8(out *helloReader) bool {
+	if len(*r) < 1 {
+		return false
+	}
+
+	length := int((*r)[0])
+	if len(*r) < 1+length {
+		return false
+	}
+
+	*out = (*r)[1 : 1+length]
+	*r = (*r)[1+length:]
+
+	return true
+}
+
+func (r *helloReader) vector16(out *helloReader) bool {
+	if len(*r) < 2 {
+		return false
+	}
+
+	length := int((*r)[0])<<8 | int((*r)[1])
+	if len(*r) < 2+length {
+		return false
+	}
+
+	*out = (*r)[2 : 2+length]
+	*r = (*r)[2+length:]
+
+	return true
+}
+
+func (r helloReader) uint16s() ([]uint16, bool) {
+	if len(r)%2 != 0 {
+		return nil, false
+	}
+
+	values := make([]uint16, 0, len(r)/2)
+	for len(r) > 0 {
+		v, _ := r.uint16()
+		values = append(values, v)
+	}
+
+	return values, true
+}
+
+type helloConnKey struct{}
+
+// helloConnContext implements http.Server.ConnContext, making the fingerprint
+// of the connection available to the handlers.
+func helloConnContext(ctx context.Context, conn net.Conn) context.Context {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		if hc, ok := tlsConn.NetConn().(*helloConn); ok {
+			return context.WithValue(ctx, helloConnKey{}, hc)
+		}
+	}
+
+	return ctx
+}
+
+// requestFingerprint returns the fingerprint of the client, or nil if the
+// request was not made over TLS.
+func requestFingerprint(req *http.Request) *clientFingerprint {
+	if req.TLS == nil {
+		return nil
+	}
+
+	if hc, ok := req.Context().Value(helloConnKey{}).(*helloConn); ok {
+		return hc.fingerprint
+	}
+
+	return nil
+}
+
+// connFingerprint returns the fingerprint of the client of a TLS connection
+// after the handshake.
+func connFingerprint(conn *tls.Conn) *clientFingerprint {
+	if hc, ok := conn.NetConn().(*helloConn); ok {
+		return hc.fingerprint
+	}
+
+	return nil
+}
+
+// fingerprintHeaders adds the fingerprints of the client to the response
+// headers of TLS requests.
+func fingerprintHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if fp := requestFingerprint(req); fp != nil {
+			w.Header().Set("X-JA3-Fingerprint", fp.JA3Hash)
+			w.Header().Set("X-JA4-Fingerprint", fp.JA4)
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+type testExtension struct {
+	id   uint16
+	data []byte
+}
+
+// vector returns data prefixed with its length of size bytes.
+func vector(size int, data ...byte) []byte {
+	length := make([]byte, size)
+	for i := range length {
+		length[i] = byte(len(data) >> (8 * (size - 1 - i)))
+	}
+
+	return append(length, data...)
+}
+
+func uint16Bytes(values ...uint16) []byte {
+	b := []byte{}
+	for _, v := range values {
+		b = binary.BigEndian.AppendUint16(b, v)
+	}
+
+	return b
+}
+
+// newClientHello returns the body of a ClientHello handshake message.
+func newClientHello(version uint16, ciphers []uint16, extensions []testExtension) []byte {
+	b := uint16Bytes(version)
+	b = append(b, make([]byte, 32)...)      // Random
+	b = append(b, vector(1, 1, 2, 3, 4)...) // Session ID
+	b = append(b, vector(2, uint16Bytes(ciphers...)...)...)
+	b = append(b, vector(1, 0)...) // Compression methods
+
+	exts := []byte{}
+	for _, ext := range extensions {
+		exts = append(exts, uint16Bytes(ext.id)...)
+		exts = append(exts, vector(2, ext.data...)...)
+	}
+
+	return append(b, vector(2, exts...)...)
+}
+
+// newHandshakeRecords wraps a ClientHello into handshake records of at most
+// size bytes.
+func newHandshakeRecords(hello []byte, size int) []byte {
+	msg := append([]byte{1}, vector(3, hello...)...)
+
+	records := []byte{}
+	for len(msg) > 0 {
+		n := min(size, len(msg))
+		records = append(records, 22, 3, 1)
+		records = append(records, vector(2, msg[:n]...)...)
+		msg = msg[n:]
+	}
+
+	return records
+}
+
+// chromeHello is the Chrome ClientHello of the JA4 specification example,
+// with GREASE values.
+func chromeHello() []byte {
+	return newClientHello(0x0303,
+		[]uint16{0x0a0a, 0x1301, 0x1302, 0x1303, 0xc02b, 0xc02f, 0xc02c, 0xc030, 0xcca9, 0xcca8, 0xc013, 0xc014, 0x009c, 0x009d, 0x002f, 0x0035},
+		[]testExtension{
+			{0x1a1a, nil},
+			{0x0000, vector(2, append([]byte{0}, vector(2, []byte("example.com")...)...)...)},
+			{0x0017, nil},
+			{0xff01, []byte{0}},
+			{0x000a, vector(2, uint16Bytes(0x3a3a, 0x001d, 0x0017, 0x0018)...)},
+			{0x000b, vector(1, 0)},
+			{0x0023, nil},
+			{0x0010, vector(2, append(vector(1, []byte("h2")...), vector(1, []byte("http/1.1")...)...)...)},
+			{0x0005, []byte{1, 0, 0, 0, 0}},
+			{0x000d, vector(2, uint16Bytes(0x0403, 0x0804, 0x0401, 0x0503, 0x0805, 0x0501, 0x0806, 0x0601)...)},
+			{0x0012, nil},
+			{0x0033, vector(2, uint16Bytes(0x001d, 0)...)},
+			{0x002d, vector(1, 1)},
+			{0x002b, vector(1, uint16Bytes(0x4a4a, 0x0304, 0x0303)...)},
+			{0x001b, vector(1, 0, 2)},
+			{0x4469, nil},
+			{0x0015, make([]byte, 8)},
+			{0x2a2a, []byte{0}},
+		})
+}
+
+// ja3ExampleHello is the ClientHello of the JA3 string in the JA3
+// documentation.
+func ja3ExampleHello() []byte {
+	return newClientHello(0x0301,
+		[]uint16{47, 53, 5, 10, 49161, 49162, 49171, 49172, 50, 56, 19, 4},
+		[]testExtension{
+			{0, vector(2, append([]byte{0}, vector(2, []byte("example.com")...)...)...)},
+			{10, vector(2, uint16Bytes(23, 24, 25)...)},
+			{11, vector(1, 0)},
+		})
+}
+
+func TestClientHelloFingerprint(t *testing.T) {
+	tests := []struct {
+		name    string
+		hello   []byte
+		ja3     string
+		ja3Hash string
+		ja4     string
+	}{
+		{
+			name:    "chrome",
+			hello:   chromeHello(),
+			ja3:     "771,4865-4866-4867-49195-49199-49196-49200-52393-52392-49171-49172-156-157-47-53,0-23-65281-10-11-35-16-5-13-18-51-45-43-27-17513-21,29-23-24,0",
+			ja3Hash: "cd08e31494f9531f560d64c695473da9",
+			ja4:     "t13d1516h2_8daaf6152771_e5627efa2ab1",
+		},
+		{
+			name:    "ja3 example",
+			hello:   ja3ExampleHello(),
+			ja3:     "769,47-53-5-10-49161-49162-49171-49172-50-56-19-4,0-10-11,23-24-25,0",
+			ja3Hash: "ada70206e40642a3e4461f35503241d5",
+			ja4:     "t10d120300_d94e65cdb899_33a13ba74d1c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, complete, ok := handshakeMessage(newHandshakeRecords(tt.hello, 64))
+			if !ok || !complete {
+				t.Fatalf("handshakeMessage() complete = %v, ok = %v", complete, ok)
+			}
+
+			hello, ok := parseClientHello(msg)
+			if !ok {
+				t.Fatal("parseClientHello() failed")
+			}
+
+			fp := hello.fingerprint()
+			if fp.JA3 != tt.ja3 {
+				t.Errorf("JA3 = %q, want %q", fp.JA3, tt.ja3)
+			}
+
+			if fp.JA3Hash != tt.ja3Hash {
+				t.Errorf("JA3 hash = %q, want %q", fp.JA3Hash, tt.ja3Hash)
+			}
+
+			if fp.JA4 != tt.ja4 {
+				t.Errorf("JA4 = %q, want %q", fp.JA4, tt.ja4)
+			}
+		})
+	}
+}
+
+func TestHandshakeMessage(t *testing.T) {
+	records := newHandshakeRecords(chromeHello(), 100)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		complete bool
+		ok       bool
+	}{
+		{"complete", records, true, true},
+		{"partial record", records[:50], false, true},
+		{"partial message", records[:105], false, true},
+		{"not handshake", append([]byte{23}, records[1:]...), false, false},
+		{"record header only", records[:5], false, true},
+		{"server hello", append([]byte{22, 3, 1, 0, 4}, 2, 0, 0, 0), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, complete, ok := handshakeMessage(tt.data)
+			if complete != tt.complete || ok != tt.ok {
+				t.Errorf("handshakeMessage() complete = %v, ok = %v, want %v, %v", complete, ok, tt.complete, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseClientHelloInvalid(t *testing.T) {
+	hello := chromeHello()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated random", hello[:20]},
+		{"truncated ciphers", hello[:45]},
+		{"truncated extensions", hello[:len(hello)-3]},
+		{"odd cipher length", append(append(append(uint16Bytes(0x0303), make([]byte, 33)...), vector(2, 0x13, 0x01, 0x13)...), vector(1, 0)...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := parseClientHello(tt.data); ok {
+				t.Error("parseClientHello() succeeded")
+			}
+		})
+	}
+}
+
+func TestIsGREASE(t *testing.T) {
+	tests := []struct {
+		value uint16
+		want  bool
+	}{
+		{0x0a0a, true},
+		{0x1a1a, true},
+		{0xfafa, true},
+		{0x0a1a, false},
+		{0x1301, false},
+		{0x0000, false},
+	}
+
+	for _, tt := range tests {
+		if got := isGREASE(tt.value); got != tt.want {
+			t.Errorf("isGREASE(%#04x) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/attilabuti/echo-server/echopb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// echoService implements the gRPC echo service (see echopb/echo.proto).
+type echoService struct {
+	echopb.UnimplementedEchoServer
+}
+
+func newGRPCServer(tls bool) *grpc.Server {
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(logUnaryRPC),
+		grpc.StreamInterceptor(logStreamRPC),
+	}
+
+	if tls {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config.https.tlsConfig)))
+	}
+
+	server := grpc.NewServer(opts...)
+	echopb.RegisterEchoServer(server, &echoService{})
+	reflection.Register(server)
+
+	return server
+}
+
+func (e *echoService) Echo(ctx context.Context, req *echopb.EchoRequest) (*echopb.EchoResponse, error) {
+	return newEchoResponse(ctx, req.GetMessage(), 0), nil
+}
+
+func (e *echoService) ServerStream(req *echopb.ServerStreamRequest, stream echopb.Echo_ServerStreamServer) error {
+	count := int(req.GetCount())
+	if count < 0 || count > maxLines {
+		return status.Errorf(codes.InvalidArgument, "invalid count: %d (max. %d)", count, maxLines)
+	} else if count == 0 {
+		count = 1
+	}
+
+	interval, err := durationArg(req.GetInterval().AsDuration())
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < count; i++ {
+		if i > 0 && !sleepRPC(stream.Context(), interval) {
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+
+		if err := stream.Send(newEchoResponse(stream.Context(), req.GetMessage(), i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *echoService) ClientStream(stream echopb.Echo_ClientStreamServer) error {
+	messages := []string{}
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		messages = append(messages, req.GetMessage())
+	}
+
+	return stream.SendAndClose(&echopb.ClientStreamResponse{
+		Messages: messages,
+		Metadata: incomingMetadata(stream.Context()),
+		Peer:     peerAddr(stream.Context()),
+	})
+}
+
+func (e *echoService) BidiStream(stream echopb.Echo_BidiStreamServer) error {
+	for i := 0; ; i++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := stream.Send(newEchoResponse(stream.Context(), req.GetMessage(), i)); err != nil {
+			return err
+		}
+	}
+}
+
+// Status responds with the status code and message of the request.
+func (e *echoService) Status(ctx context.Context, req *echopb.StatusRequest) (*echopb.EchoResponse, error) {
+	code := req.GetCode()
+	if code < 0 || code > int32(codes.Unauthenticated) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid status code: %d", code)
+	}
+
+	if codes.Code(code) == codes.OK {
+		return newEchoResponse(ctx, req.GetMessage(), 0), nil
+	}
+
+	return nil, status.Error(codes.Code(code), req.GetMessage())
+}
+
+// Delay echoes the message after the delay of the request (at most maxDelay).
+func (e *echoService) Delay(ctx context.Context, req *echopb.DelayRequest) (*echopb.EchoResponse, error) {
+	delay, err := durationArg(req.GetDelay().AsDuration())
+	if err != nil {
+		return nil, err
+	}
+
+	if !sleepRPC(ctx, delay) {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	return newEchoResponse(ctx, req.GetMessage(), 0), nil
+}
+
+func newEchoResponse(ctx context.Context, message string, index int) *echopb.EchoResponse {
+	return &echopb.EchoResponse{
+		Message:  message,
+		Metadata: incomingMetadata(ctx),
+		Peer:     peerAddr(ctx),
+		Index:    int32(index),
+	}
+}
+
+func incomingMetadata(ctx context.Context) map[string]*echopb.MetadataValues {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := make(map[string]*echopb.MetadataValues, len(md))
+	for key, v := range md {
+		values[key] = &echopb.MetadataValues{Values: v}
+	}
+
+	return values
+}
+
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+
+	return ""
+}
+
+// durationArg validates a delay argument and limits it to maxDelay.
+func durationArg(d time.Duration) (time.Duration, error) {
+	if d < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid duration: %v", d)
+	}
+
+	if d > maxDelay {
+		d = maxDelay
+	}
+
+	return d, nil
+}
+
+// sleepRPC waits for d, and reports false if the RPC was canceled meanwhile.
+func sleepRPC(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func logUnaryRPC(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	log.rpc(peerAddr(ctx), info.FullMethod, status.Code(err).String())
+
+	return resp, err
+}
+
+func logStreamRPC(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	err := handler(srv, stream)
+	log.rpc(peerAddr(stream.Context()), info.FullMethod, status.Code(err).String())
+
+	return err
+}
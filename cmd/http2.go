@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"net/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// httpsProtocols returns the protocols served by the HTTPS server.
+func httpsProtocols(http2 bool) *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(http2)
+
+	return protocols
+}
+
+func newHTTP2Config() *http.HTTP2Config {
+	return &http.HTTP2Config{MaxConcurrentStreams: config.http2.maxConcurrentStreams}
+}
+
+// h2cHandler serves cleartext HTTP/2 connections started with prior knowledge
+// or with an "Upgrade: h2c" request. net/http does not support the Upgrade
+// mechanism, so both are handled by golang.org/x/net/http2/h2c.
+func h2cHandler(next http.Handler) http.Handler {
+	return h2c.NewHandler(next, &http2.Server{MaxConcurrentStreams: uint32(config.http2.maxConcurrentStreams)})
+}
+
+// requestProtocol returns the protocol of the request for the request log,
+// distinguishing HTTP/2 over TLS (h2) and cleartext HTTP/2 (h2c).
+func requestProtocol(req *http.Request) string {
+	if req.ProtoMajor != 2 {
+		return req.Proto
+	}
+
+	if req.TLS != nil {
+		return req.Proto + " (h2)"
+	}
+
+	return req.Proto + " (h2c)"
+}
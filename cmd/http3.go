@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+func (s *appServer) newHTTP3Server() *http3.Server {
+	return &http3.Server{
+		Handler:   s.handler,
+		TLSConfig: config.https.tlsConfig,
+	}
+}
+
+// altSvcHeader returns the Alt-Svc header value advertising the bound ports of
+// the HTTP/3 servers, or an empty string if no HTTP/3 server is started. It is
+// called once the servers listen, so random ports are advertised as bound.
+func (s *appServer) altSvcHeader() string {
+	var services []string
+	seen := map[int]bool{}
+	for _, l := range s.listeners {
+		h3, ok := l.(*http3Listener)
+		if !ok {
+			continue
+		}
+
+		addr, ok := h3.addr().(*net.UDPAddr)
+		if !ok || seen[addr.Port] {
+			continue
+		}
+
+		seen[addr.Port] = true
+		services = append(services, fmt.Sprintf(`h3=":%d"; ma=%d`, addr.Port, int(config.http3.altSvcMaxAge.Seconds())))
+	}
+
+	return strings.Join(services, ", ")
+}
+
+// altSvc advertises the HTTP/3 servers in the responses of the HTTPS server.
+// HTTP/3 serves https origins only, so cleartext responses do not advertise it.
+func (s *appServer) altSvc(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if len(s.altSvcValue) > 0 && req.TLS != nil && req.ProtoMajor < 3 {
+			w.Header().Set("Alt-Svc", s.altSvcValue)
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,587 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"google.golang.org/grpc"
+	"gopkg.in/yaml.v3"
+)
+
+// Protocols of the listeners.
+const (
+	protoHTTP   = "http"
+	protoHTTPS  = "https"
+	protoHTTP3  = "http3"
+	protoGRPC   = "grpc"
+	protoTCP    = "tcp"
+	protoTLSTCP = "tls-tcp"
+	protoUDP    = "udp"
+)
+
+// listenerNetworks are the networks of the protocols, the first one is the
+// default.
+var listenerNetworks = map[string][]string{
+	protoHTTP:   {"tcp", "tcp4", "tcp6", "unix"},
+	protoHTTPS:  {"tcp", "tcp4", "tcp6"},
+	protoHTTP3:  {"udp", "udp4", "udp6"},
+	protoGRPC:   {"tcp", "tcp4", "tcp6"},
+	protoTCP:    {"tcp", "tcp4", "tcp6", "unix", "unixpacket"},
+	protoTLSTCP: {"tcp", "tcp4", "tcp6", "unix"},
+	protoUDP:    {"udp", "udp4", "udp6", "unixgram"},
+}
+
+// listenerConfig is an entry of the listeners configuration. A server is
+// started on each port of the entry, or on the socket of a Unix network.
+type listenerConfig struct {
+	Protocol string   `yaml:"protocol"` // http, https, http3, grpc, tcp, tls-tcp or udp
+	Network  string   `yaml:"network"`  // Network of the protocol (see listenerNetworks), the first one if empty
+	Hosts    hostList `yaml:"host"`     // Host names or IP addresses to bind to, the host option if empty
+	Port     string   `yaml:"port"`     // Ports and port ranges (e.g. 7,8080-8089), 0 or empty for a random port
+	Socket   string   `yaml:"socket"`   // Path of the Unix domain socket (unix, unixpacket and unixgram)
+	Mode     string   `yaml:"mode"`     // Octal file mode of the Unix domain socket (e.g. 0660)
+	H2C      bool     `yaml:"h2c"`      // Cleartext HTTP/2 enabled (http)
+	HTTP2    *bool    `yaml:"http2"`    // HTTP/2 enabled (https), true if not set
+	TLS      bool     `yaml:"tls"`      // Serve over TLS (grpc)
+
+	ports []int
+	binds []bindAddress
+	mode  os.FileMode
+}
+
+// bindAddress is a network address a server listens on.
+type bindAddress struct {
+	network string
+	host    string
+}
+
+// hostList is a host or a list of hosts in the configuration file.
+type hostList []string
+
+func (h *hostList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*h = hostList{value.Value}
+		return nil
+	}
+
+	var hosts []string
+	if err := value.Decode(&hosts); err != nil {
+		return err
+	}
+
+	*h = hosts
+
+	return nil
+}
+
+func (l *listenerConfig) init() error {
+	networks, ok := listenerNetworks[l.Protocol]
+	if !ok {
+		return fmt.Errorf("invalid listener protocol: %q", l.Protocol)
+	}
+
+	if len(l.Network) == 0 {
+		l.Network = networks[0]
+	} else if !slices.Contains(networks, l.Network) {
+		return fmt.Errorf("invalid %s listener network: %q (%s)", l.Protocol, l.Network, strings.Join(networks, ", "))
+	}
+
+	if l.isUnix() {
+		return l.initSocket()
+	}
+
+	var err error
+	if l.ports, err = parsePorts(l.Port); err != nil {
+		return fmt.Errorf("invalid %s listener port: %v", l.Protocol, err)
+	}
+
+	if l.binds, err = resolveHosts(l.Hosts, l.Network); err != nil {
+		return fmt.Errorf("invalid %s listener host: %v", l.Protocol, err)
+	}
+
+	if l.HTTP2 == nil {
+		http2 := true
+		l.HTTP2 = &http2
+	}
+
+	return nil
+}
+
+// usesTLS reports whether the servers of the listener use TLS.
+func (l *listenerConfig) usesTLS() bool {
+	switch l.Protocol {
+	case protoHTTPS, protoHTTP3, protoTLSTCP:
+		return true
+	case protoGRPC:
+		return l.TLS
+	}
+
+	return false
+}
+
+func (l *listenerConfig) initSocket() error {
+	if len(l.Socket) == 0 {
+		return fmt.Errorf("%s listener socket must be set with network %s", l.Protocol, l.Network)
+	}
+
+	if len(l.Mode) > 0 {
+		mode, err := strconv.ParseUint(l.Mode, 8, 32)
+		if err != nil || mode > 0o777 {
+			return fmt.Errorf("invalid %s listener socket mode: %s", l.Protocol, l.Mode)
+		}
+
+		l.mode = os.FileMode(mode)
+	}
+
+	return nil
+}
+
+// isUnix reports whether the listener uses a Unix domain socket.
+func (l *listenerConfig) isUnix() bool {
+	return strings.HasPrefix(l.Network, "unix")
+}
+
+func (l *listenerConfig) isHTTP() bool {
+	return l.Protocol == protoHTTP || l.Protocol == protoHTTPS || l.Protocol == protoHTTP3
+}
+
+// addresses returns the addresses of the servers of the listener, one for
+// each host and port.
+func (l *listenerConfig) addresses() []bindAddress {
+	if l.isUnix() {
+		return []bindAddress{{network: l.Network, host: l.Socket}}
+	}
+
+	addresses := make([]bindAddress, 0, len(l.binds)*len(l.ports))
+	for _, bind := range l.binds {
+		for _, port := range l.ports {
+			addresses = append(addresses, bindAddress{network: bind.network, host: net.JoinHostPort(bind.host, strconv.Itoa(port))})
+		}
+	}
+
+	return addresses
+}
+
+// resolveHosts resolves the hosts to the IP addresses of the network. Each
+// address gets an IPv4 or IPv6 only network, so that an IPv4 and an IPv6
+// address can be bound separately. An empty host binds to all interfaces.
+func resolveHosts(hosts []string, network string) ([]bindAddress, error) {
+	if len(hosts) == 0 {
+		hosts = []string{""}
+	}
+
+	binds := []bindAddress{}
+	seen := map[bindAddress]bool{}
+	for _, host := range hosts {
+		host = strings.Trim(strings.TrimSpace(host), "[]")
+		if len(host) == 0 {
+			binds = append(binds, bindAddress{network: network})
+			continue
+		}
+
+		addrs, err := resolveHost(host, network)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, addr := range addrs {
+			bind := bindAddress{network: ipNetwork(network, addr), host: addr.String()}
+			if !seen[bind] {
+				seen[bind] = true
+				binds = append(binds, bind)
+			}
+		}
+	}
+
+	return binds, nil
+}
+
+// resolveHost returns the IP addresses of a host name or IP address, limited
+// to the IP version of the network (e.g. tcp4).
+func resolveHost(host string, network string) ([]netip.Addr, error) {
+	version := network[len(network)-1:]
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addr = addr.Unmap()
+		if (version == "4" && !addr.Is4()) || (version == "6" && !addr.Is6()) {
+			return nil, fmt.Errorf("%s is not an IPv%s address", host, version)
+		}
+
+		return []netip.Addr{addr}, nil
+	}
+
+	lookup := "ip"
+	if version == "4" || version == "6" {
+		lookup += version
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(context.Background(), lookup, host)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve %s: %v", host, err)
+	} else if len(addrs) == 0 {
+		return nil, fmt.Errorf("could not resolve %s: no addresses", host)
+	}
+
+	for i, addr := range addrs {
+		addrs[i] = addr.Unmap()
+	}
+
+	return addrs, nil
+}
+
+// ipNetwork returns the IPv4 or IPv6 only network (e.g. tcp4) of an address.
+func ipNetwork(network string, addr netip.Addr) string {
+	network = strings.TrimRight(network, "46")
+	if addr.Is4() {
+		return network + "4"
+	}
+
+	return network + "6"
+}
+
+// parsePorts parses a comma separated list of ports and port ranges (e.g.
+// 7,7007,8080-8089).
+func parsePorts(value string) ([]int, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return []int{0}, nil
+	}
+
+	ports := []int{}
+	seen := map[int]bool{}
+	for _, item := range strings.Split(value, ",") {
+		items, err := parsePortRange(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, port := range items {
+			if port != 0 && seen[port] {
+				return nil, fmt.Errorf("duplicate port: %d", port)
+			}
+
+			seen[port] = true
+			ports = append(ports, port)
+		}
+	}
+
+	return ports, nil
+}
+
+// parsePortRange parses a port or a port range (e.g. 8080-8089).
+func parsePortRange(value string) ([]int, error) {
+	first, last, isRange := strings.Cut(value, "-")
+
+	from, err := strconv.Atoi(strings.TrimSpace(first))
+	if err != nil || !isValidPort(from) {
+		return nil, fmt.Errorf("invalid port number: %s", first)
+	}
+
+	if !isRange {
+		return []int{from}, nil
+	}
+
+	to, err := strconv.Atoi(strings.TrimSpace(last))
+	if err != nil || !isValidPort(to) || to < from || from == 0 {
+		return nil, fmt.Errorf("invalid port range: %s", value)
+	}
+
+	ports := make([]int, 0, to-from+1)
+	for port := from; port <= to; port++ {
+		ports = append(ports, port)
+	}
+
+	return ports, nil
+}
+
+// listener is a server of appServer, started on one port of a listener
+// configuration.
+type listener interface {
+	listen() error  // Opens the socket of the server
+	serve() error   // Serves until close is called, nil if it was closed
+	close() error   // Stops the server
+	addr() net.Addr // Address of the open socket
+	String() string // Name of the server used in the log
+}
+
+// newListeners creates the servers of a listener configuration, one for each
+// address.
+func (s *appServer) newListeners(l *listenerConfig) []listener {
+	addresses := l.addresses()
+	listeners := make([]listener, len(addresses))
+	for i, a := range addresses {
+		network, address := a.network, a.host
+
+		switch l.Protocol {
+		case protoHTTP:
+			listeners[i] = s.newHTTPListener(network, address, l.H2C)
+		case protoHTTPS:
+			listeners[i] = s.newHTTPSListener(network, address, *l.HTTP2)
+		case protoHTTP3:
+			listeners[i] = &http3Listener{network: network, address: address, server: s.newHTTP3Server()}
+		case protoGRPC:
+			listeners[i] = &grpcListener{network: network, address: address, tls: l.TLS, server: newGRPCServer(l.TLS)}
+		case protoTCP:
+			listeners[i] = &tcpEchoListener{network: network, address: address}
+		case protoTLSTCP:
+			listeners[i] = &tcpEchoListener{network: network, address: address, tls: true}
+		case protoUDP:
+			listeners[i] = &udpEchoListener{network: network, address: address}
+		}
+
+		if l.isUnix() {
+			listeners[i] = &socketListener{listener: listeners[i], network: network, path: address, mode: l.mode}
+		}
+	}
+
+	return listeners
+}
+
+// socketListener is a server listening on a Unix domain socket. It removes the
+// socket file left by a previous run, sets the mode of the new socket file and
+// removes it when the server is stopped.
+type socketListener struct {
+	listener
+	network string
+	path    string
+	mode    os.FileMode
+}
+
+func (l *socketListener) listen() error {
+	if info, err := os.Lstat(l.path); err == nil && info.Mode().Type() == os.ModeSocket {
+		if err := l.checkStale(); err != nil {
+			return err
+		}
+
+		if err := os.Remove(l.path); err != nil {
+			return err
+		}
+	}
+
+	if l.mode == 0 {
+		return l.listener.listen()
+	}
+
+	// The socket is created without access for the group and others, and gets
+	// its mode once it exists.
+	if err := listenPrivate(l.listener.listen); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(l.path, l.mode); err != nil {
+		l.listener.close()
+		os.Remove(l.path)
+		return err
+	}
+
+	return nil
+}
+
+// checkStale returns an error unless the existing socket file is left by a
+// previous run, i.e. connecting to it is refused.
+func (l *socketListener) checkStale() error {
+	conn, err := net.DialTimeout(l.network, l.path, time.Second)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("socket %s is in use by another process", l.path)
+	}
+
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("could not check socket %s: %v", l.path, err)
+	}
+
+	return nil
+}
+
+func (l *socketListener) close() error {
+	err := l.listener.close()
+
+	if rerr := os.Remove(l.path); rerr != nil && !errors.Is(rerr, os.ErrNotExist) {
+		log.error.Printf("error while removing socket file: %v\n", rerr)
+	}
+
+	return err
+}
+
+func (s *appServer) baseContext(net.Listener) context.Context {
+	return s.ctx
+}
+
+type httpListener struct {
+	name     string
+	network  string
+	address  string
+	tls      bool
+	server   *http.Server
+	listener net.Listener
+}
+
+func (s *appServer) newHTTPListener(network, address string, h2c bool) *httpListener {
+	server := &http.Server{
+		Addr:        address,
+		Handler:     s.handler,
+		ErrorLog:    log.error,
+		BaseContext: s.baseContext,
+	}
+
+	if h2c {
+		server.Handler = h2cHandler(s.handler)
+	}
+
+	return &httpListener{name: "HTTP server", network: network, address: address, server: server}
+}
+
+func (s *appServer) newHTTPSListener(network, address string, http2 bool) *httpListener {
+	server := &http.Server{
+		Addr:        address,
+		Handler:     s.handler,
+		TLSConfig:   config.https.tlsConfig,
+		ErrorLog:    log.error,
+		BaseContext: s.baseContext,
+		ConnContext: helloConnContext,
+		Protocols:   httpsProtocols(http2),
+		HTTP2:       newHTTP2Config(),
+	}
+
+	return &httpListener{name: "HTTPS server", network: network, address: address, tls: true, server: server}
+}
+
+func (l *httpListener) listen() (err error) {
+	l.listener, err = net.Listen(l.network, l.address)
+
+	return
+}
+
+func (l *httpListener) serve() (err error) {
+	if l.tls {
+		err = l.server.ServeTLS(&helloListener{l.listener}, "", "")
+	} else {
+		err = l.server.Serve(l.listener)
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return
+}
+
+// close shuts the server down gracefully, and closes the remaining
+// connections if it does not finish within shutdownTimeout.
+func (l *httpListener) close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := l.server.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return l.server.Close()
+	}
+
+	return err
+}
+
+func (l *httpListener) addr() net.Addr {
+	if l.listener == nil {
+		return nil
+	}
+
+	return l.listener.Addr()
+}
+
+func (l *httpListener) String() string {
+	return l.name
+}
+
+type http3Listener struct {
+	network string
+	address string
+	server  *http3.Server
+	conn    net.PacketConn
+}
+
+func (l *http3Listener) listen() (err error) {
+	l.conn, err = net.ListenPacket(l.network, l.address)
+
+	return
+}
+
+func (l *http3Listener) serve() error {
+	if err := l.server.Serve(l.conn); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// close stops the server and closes the connection, which is not closed by
+// http3.Server.Close.
+func (l *http3Listener) close() error {
+	if err := l.server.Close(); err != nil {
+		return err
+	}
+
+	return l.conn.Close()
+}
+
+func (l *http3Listener) addr() net.Addr {
+	if l.conn == nil {
+		return nil
+	}
+
+	return l.conn.LocalAddr()
+}
+
+func (l *http3Listener) String() string {
+	return "HTTP/3 server"
+}
+
+type grpcListener struct {
+	network  string
+	address  string
+	tls      bool
+	server   *grpc.Server
+	listener net.Listener
+}
+
+func (l *grpcListener) listen() (err error) {
+	l.listener, err = net.Listen(l.network, l.address)
+
+	return
+}
+
+func (l *grpcListener) serve() error {
+	return l.server.Serve(l.listener)
+}
+
+func (l *grpcListener) close() error {
+	l.server.Stop()
+
+	return nil
+}
+
+func (l *grpcListener) addr() net.Addr {
+	if l.listener == nil {
+		return nil
+	}
+
+	return l.listener.Addr()
+}
+
+func (l *grpcListener) String() string {
+	if l.tls {
+		return "gRPC server (TLS)"
+	}
+
+	return "gRPC server"
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePorts(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []int
+		wantErr bool
+	}{
+		{"", []int{0}, false},
+		{"0", []int{0}, false},
+		{"7", []int{7}, false},
+		{" 7 , 7007 ", []int{7, 7007}, false},
+		{"8080-8083", []int{8080, 8081, 8082, 8083}, false},
+		{"7,8080 - 8081", []int{7, 8080, 8081}, false},
+		{"65535-65535", []int{65535}, false},
+		{"0,0", []int{0, 0}, false},
+		{"7,7", nil, true},
+		{"7,5-8", nil, true},
+		{"8083-8080", nil, true},
+		{"0-5", nil, true},
+		{"1-65536", nil, true},
+		{"65536", nil, true},
+		{"-1", nil, true},
+		{"7,", nil, true},
+		{"http", nil, true},
+		{"1-2-3", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parsePorts(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePorts(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePorts(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveHosts(t *testing.T) {
+	tests := []struct {
+		name    string
+		hosts   []string
+		network string
+		want    []bindAddress
+		wantErr bool
+	}{
+		{"no host", nil, "tcp", []bindAddress{{"tcp", ""}}, false},
+		{"empty host", []string{" "}, "udp4", []bindAddress{{"udp4", ""}}, false},
+		{"dual-stack", []string{"0.0.0.0", "::"}, "tcp", []bindAddress{{"tcp4", "0.0.0.0"}, {"tcp6", "::"}}, false},
+		{"brackets", []string{"[::1]"}, "udp", []bindAddress{{"udp6", "::1"}}, false},
+		{"duplicates", []string{"127.0.0.1", "127.0.0.1", "[::1]", "::1"}, "tcp", []bindAddress{{"tcp4", "127.0.0.1"}, {"tcp6", "::1"}}, false},
+		{"IPv4-mapped", []string{"::ffff:127.0.0.1"}, "tcp4", []bindAddress{{"tcp4", "127.0.0.1"}}, false},
+		{"IPv6 on tcp4", []string{"::1"}, "tcp4", nil, true},
+		{"IPv4 on udp6", []string{"127.0.0.1"}, "udp6", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveHosts(tt.hosts, tt.network)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveHosts(%q, %s) error = %v, wantErr %v", tt.hosts, tt.network, err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveHosts(%q, %s) = %v, want %v", tt.hosts, tt.network, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListenerAddresses(t *testing.T) {
+	l := &listenerConfig{
+		Protocol: protoTCP,
+		Hosts:    hostList{"127.0.0.1", "::1"},
+		Port:     "7,8",
+	}
+
+	if err := l.init(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []bindAddress{{"tcp4", "127.0.0.1:7"}, {"tcp4", "127.0.0.1:8"}, {"tcp6", "[::1]:7"}, {"tcp6", "[::1]:8"}}
+	if got := l.addresses(); !reflect.DeepEqual(got, want) {
+		t.Errorf("addresses() = %v, want %v", got, want)
+	}
+}
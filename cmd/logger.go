@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strings"
 	"time"
 )
 
@@ -87,7 +88,22 @@ func (l *logger) close() {
 func (l *logger) request(next http.Handler) http.Handler {
 	if l.requestEnabled {
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-			l.requestLogger.Printf("%s - [%s] %s %s", req.RemoteAddr, req.Method, req.Proto, req.URL)
+			msg := fmt.Sprintf("%s - [%s] %s %s", req.RemoteAddr, req.Method, requestProtocol(req), req.URL)
+
+			if identity := clientIdentity(req.TLS); len(identity) > 0 {
+				verified := "unverified"
+				if len(req.TLS.VerifiedChains) > 0 {
+					verified = "verified"
+				}
+
+				msg += fmt.Sprintf(" - client: %s (%s)", identity, verified)
+			}
+
+			if fp := requestFingerprint(req); fp != nil {
+				msg += fmt.Sprintf(" - JA3: %s, JA4: %s", fp.JA3Hash, fp.JA4)
+			}
+
+			l.requestLogger.Print(msg)
 			next.ServeHTTP(w, req)
 		})
 	}
@@ -97,15 +113,26 @@ func (l *logger) request(next http.Handler) http.Handler {
 	})
 }
 
-func (l *logger) connection(open bool, addr string) {
+// rpc logs a completed gRPC call with its status code.
+func (l *logger) rpc(addr string, method string, code string) {
+	if l.requestEnabled {
+		l.requestLogger.Printf("%s - [gRPC] %s - %s", addr, method, code)
+	}
+}
+
+// connection logs an opened or closed connection. The details, e.g. the
+// parameters of a TLS handshake, are appended to the message.
+func (l *logger) connection(open bool, network string, addr string, details ...string) {
 	if !l.connEnabled {
 		return
 	}
 
-	if open {
-		l.connLogger.Printf("%s - new TCP connection", addr)
+	if open && len(details) > 0 {
+		l.connLogger.Printf("%s - new %s connection (%s)", addr, network, strings.Join(details, ", "))
+	} else if open {
+		l.connLogger.Printf("%s - new %s connection", addr, network)
 	} else {
-		l.connLogger.Printf("%s - TCP connection closed", addr)
+		l.connLogger.Printf("%s - %s connection closed", addr, network)
 	}
 }
 
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// handleRedirect redirects n times before ending up at /anything. The
+// redirects are relative unless the absolute query parameter is true.
+func (s *appServer) handleRedirect(w http.ResponseWriter, req *http.Request) {
+	n, err := redirectCount(req, "/redirect/")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if absolute, _ := strconv.ParseBool(req.URL.Query().Get("absolute")); absolute {
+		redirectTo(w, absoluteURL(req, redirectPath("/absolute-redirect/", n-1)), http.StatusFound)
+	} else {
+		redirectTo(w, redirectPath("/relative-redirect/", n-1), http.StatusFound)
+	}
+}
+
+func (s *appServer) handleRelativeRedirect(w http.ResponseWriter, req *http.Request) {
+	n, err := redirectCount(req, "/relative-redirect/")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	redirectTo(w, redirectPath("/relative-redirect/", n-1), http.StatusFound)
+}
+
+func (s *appServer) handleAbsoluteRedirect(w http.ResponseWriter, req *http.Request) {
+	n, err := redirectCount(req, "/absolute-redirect/")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	redirectTo(w, absoluteURL(req, redirectPath("/absolute-redirect/", n-1)), http.StatusFound)
+}
+
+// handleRedirectTo redirects to the url query parameter with the status code
+// given in the status query parameter (302 by default).
+func (s *appServer) handleRedirectTo(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	location := query.Get("url")
+	if len(location) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("url query parameter must be specified"))
+		return
+	}
+
+	status := http.StatusFound
+	if len(query.Get("status")) > 0 {
+		var err error
+		if status, err = strconv.Atoi(query.Get("status")); err != nil || status < 300 || status > 399 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid redirect status code: %s", query.Get("status")))
+			return
+		}
+	}
+
+	redirectTo(w, location, status)
+}
+
+func redirectCount(req *http.Request, prefix string) (int, error) {
+	n, err := strconv.Atoi(pathParam(req, prefix))
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid number of redirects: %s", pathParam(req, prefix))
+	}
+
+	return n, nil
+}
+
+// redirectPath returns the path of the next redirect, or /anything after the
+// last one.
+func redirectPath(prefix string, n int) string {
+	if n < 1 {
+		return "/anything"
+	}
+
+	return prefix + strconv.Itoa(n)
+}
+
+func absoluteURL(req *http.Request, path string) string {
+	u := url.URL{Scheme: "http", Host: req.Host, Path: path}
+	if req.TLS != nil {
+		u.Scheme = "https"
+	}
+
+	return u.String()
+}
+
+// redirectTo sets the Location header as is, unlike http.Redirect which
+// resolves relative locations.
+func redirectTo(w http.ResponseWriter, location string, status int) {
+	w.Header().Set("Location", location)
+	w.WriteHeader(status)
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"text/template"
+	"time"
+)
+
+// templateData is passed to the response body templates.
+type templateData struct {
+	Method   string
+	Path     string
+	Query    url.Values
+	Headers  http.Header
+	RemoteIP string
+	Body     string
+	TLS      *tlsInfo
+}
+
+var templateFuncs = template.FuncMap{
+	"uuid":    newUUID,
+	"now":     time.Now,
+	"randInt": randInt,
+	"env":     os.Getenv,
+}
+
+func parseTemplate(name string, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s template: %v", name, err)
+	}
+
+	return tmpl, nil
+}
+
+func renderTemplate(tmpl *template.Template, req *http.Request) ([]byte, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteIP, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		remoteIP = req.RemoteAddr
+	}
+
+	data := templateData{
+		Method:   req.Method,
+		Path:     req.URL.Path,
+		Query:    req.URL.Query(),
+		Headers:  req.Header,
+		RemoteIP: remoteIP,
+		Body:     string(body),
+		TLS:      newTLSInfo(req),
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("could not render %s template: %v", tmpl.Name(), err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// randInt returns a random integer in [min, max].
+func randInt(min int, max int) (int, error) {
+	if max < min {
+		return 0, fmt.Errorf("randInt: max (%d) is less than min (%d)", max, min)
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max-min)+1))
+	if err != nil {
+		return 0, err
+	}
+
+	return min + int(n.Int64()), nil
+}
+
+// randFloat returns a random number in [0.0, 1.0).
+func randFloat() (float64, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1<<53))
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(n.Int64()) / (1 << 53), nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/urfave/cli/v2"
@@ -29,7 +30,10 @@ func Execute() {
 	}
 
 	if app.run {
-		server.start()
+		if err := server.start(); err != nil {
+			fmt.Printf("%s: error: %s\n", app.name, err)
+			os.Exit(1)
+		}
 	}
 }
 
@@ -39,11 +43,16 @@ func init() {
 	}
 
 	flags := []cli.Flag{
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:  "host",
+			Usage: "Server `host` name or IP address, host names are resolved to all of their addresses (can be repeated)",
+		}),
+
 		altsrc.NewStringFlag(&cli.StringFlag{
-			Name:        "host",
-			Value:       "",
-			Usage:       "Server `host`",
-			Destination: &config.server.host,
+			Name:        "ip-version",
+			Usage:       "IP `version` of the servers (4, 6)",
+			Destination: &config.server.ipVersion,
+			DefaultText: "both",
 		}),
 
 		altsrc.NewBoolFlag(&cli.BoolFlag{
@@ -58,6 +67,12 @@ func init() {
 			Usage:       "HTTP `port`",
 			Destination: &config.http.port,
 		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "http-h2c",
+			Usage:       "Enable cleartext HTTP/2 (h2c) on the HTTP server, with prior knowledge and Upgrade",
+			Value:       false,
+			Destination: &config.http.h2c,
+		}),
 
 		altsrc.NewBoolFlag(&cli.BoolFlag{
 			Name:        "enable-https",
@@ -71,6 +86,57 @@ func init() {
 			Usage:       "HTTPS `port`",
 			Destination: &config.https.port,
 		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "https-http2",
+			Usage:       "Enable HTTP/2 on the HTTPS server",
+			Value:       true,
+			Destination: &config.https.http2,
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:        "http2-max-concurrent-streams",
+			Usage:       "Maximum number of concurrent HTTP/2 streams per connection",
+			Value:       250,
+			Destination: &config.http2.maxConcurrentStreams,
+		}),
+
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "enable-http3",
+			Usage:       "Enable HTTP/3 server (uses the HTTPS certificate and TLS options)",
+			Value:       false,
+			Destination: &config.http3.enabled,
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:        "port-http3",
+			Usage:       "HTTP/3 UDP `port`",
+			Value:       0,
+			Destination: &config.http3.port,
+			DefaultText: "HTTPS port",
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "http3-alt-svc-max-age",
+			Usage:       "Max. age of the Alt-Svc header advertising HTTP/3",
+			Value:       24 * time.Hour,
+			Destination: &config.http3.altSvcMaxAge,
+		}),
+
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "enable-grpc",
+			Usage:       "Enable gRPC server",
+			Value:       false,
+			Destination: &config.grpc.enabled,
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:        "port-grpc",
+			Value:       50051,
+			Usage:       "gRPC `port`",
+			Destination: &config.grpc.port,
+		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "grpc-tls",
+			Usage:       "Serve gRPC over TLS (uses the HTTPS certificate and TLS options)",
+			Value:       false,
+			Destination: &config.grpc.tls,
+		}),
 
 		altsrc.NewStringFlag(&cli.StringFlag{
 			Name:        "crt-file",
@@ -84,6 +150,91 @@ func init() {
 			Value:       "",
 			Destination: &config.https.key,
 		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "cert-reload-interval",
+			Usage:       "Interval of checking the certificate files for changes, 0 disables watching",
+			Value:       5 * time.Second,
+			Destination: &config.https.reloadInterval,
+		}),
+
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:  "cert-host",
+			Usage: "Additional `host` name or IP address of the generated certificate (can be repeated)",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "cert-key-type",
+			Usage:       "Key `type` of the generated certificate (rsa, ecdsa, ed25519)",
+			Value:       "rsa",
+			Destination: &config.https.certKeyType,
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "cert-validity",
+			Usage:       "Validity of the generated certificate",
+			Value:       365 * 24 * time.Hour,
+			Destination: &config.https.certValidity,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "ca-crt-file",
+			Usage:       "Location of the local CA certificate `file` signing the generated certificate, created if it does not exist",
+			Value:       "",
+			Destination: &config.https.caCert,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "ca-key-file",
+			Usage:       "Location of the local CA private key `file`, created if it does not exist",
+			Value:       "",
+			Destination: &config.https.caKey,
+		}),
+
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "client-ca-file",
+			Usage:       "Location of the CA certificates `file` used to verify client certificates",
+			Value:       "",
+			Destination: &config.https.clientCAFile,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "client-auth",
+			Usage:       "Client certificate authentication `mode` (none, request, require, verify-if-given, require-and-verify)",
+			Value:       "none",
+			Destination: &config.https.clientAuth,
+		}),
+
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "tls-preset",
+			Usage:       "TLS policy `preset` (modern, intermediate, legacy)",
+			Value:       "",
+			Destination: &config.tls.preset,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "tls-min-version",
+			Usage:       "Minimum TLS `version` (1.0, 1.1, 1.2, 1.3)",
+			Value:       "",
+			Destination: &config.tls.minVersion,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "tls-max-version",
+			Usage:       "Maximum TLS `version` (1.0, 1.1, 1.2, 1.3)",
+			Value:       "",
+			Destination: &config.tls.maxVersion,
+		}),
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:  "tls-cipher",
+			Usage: "Allowed TLS 1.0-1.2 cipher `suite`, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 (can be repeated)",
+		}),
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:  "tls-curve",
+			Usage: "Preferred `curve` (X25519, P-256, P-384, P-521) (can be repeated)",
+		}),
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:  "tls-alpn",
+			Usage: "ALPN `protocol` (can be repeated)",
+		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "tls-session-tickets",
+			Usage:       "Enable TLS session tickets",
+			Value:       true,
+			Destination: &config.tls.sessionTickets,
+		}),
 
 		altsrc.NewStringFlag(&cli.StringFlag{
 			Name:        "content",
@@ -103,6 +254,42 @@ func init() {
 			Usage:       "Content-Type header",
 			Destination: &config.content.contentType,
 		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "content-template",
+			Usage:       "Render response body as a Go template",
+			Value:       false,
+			Destination: &config.content.isTemplate,
+		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "content-compress",
+			Usage:       "Compress response body based on the Accept-Encoding header",
+			Value:       false,
+			Destination: &config.content.compress,
+		}),
+
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:  "ws-subprotocol",
+			Usage: "Supported WebSocket `subprotocol` (can be repeated)",
+		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "ws-compression",
+			Usage:       "Enable WebSocket permessage-deflate compression",
+			Value:       false,
+			Destination: &config.ws.compression,
+		}),
+
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "sse-interval",
+			Usage:       "Interval between SSE events",
+			Value:       time.Second,
+			Destination: &config.sse.interval,
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:        "sse-retry",
+			Usage:       "SSE reconnection time in milliseconds",
+			Value:       3000,
+			Destination: &config.sse.retry,
+		}),
 
 		altsrc.NewBoolFlag(&cli.BoolFlag{
 			Name:        "enable-tcp",
@@ -110,11 +297,24 @@ func init() {
 			Value:       false,
 			Destination: &config.tcp.enabled,
 		}),
-		altsrc.NewIntFlag(&cli.IntFlag{
+		altsrc.NewStringFlag(&cli.StringFlag{
 			Name:        "port-tcp",
-			Usage:       "TCP echo `port`",
+			Usage:       "TCP echo `ports`, a comma separated list of ports and port ranges (e.g. 7,7000-7100)",
+			Destination: &config.tcp.ports,
+			DefaultText: "random",
+		}),
+
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "enable-tls-tcp",
+			Usage:       "Enable TLS TCP echo server (uses the HTTPS certificate and TLS options)",
+			Value:       false,
+			Destination: &config.tlsTCP.enabled,
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:        "port-tls-tcp",
+			Usage:       "TLS TCP echo `port`",
 			Value:       0,
-			Destination: &config.tcp.port,
+			Destination: &config.tlsTCP.port,
 			DefaultText: "random",
 		}),
 
@@ -124,11 +324,10 @@ func init() {
 			Value:       false,
 			Destination: &config.udp.enabled,
 		}),
-		altsrc.NewIntFlag(&cli.IntFlag{
+		altsrc.NewStringFlag(&cli.StringFlag{
 			Name:        "port-udp",
-			Usage:       "UDP echo `port`",
-			Value:       0,
-			Destination: &config.udp.port,
+			Usage:       "UDP echo `ports`, a comma separated list of ports and port ranges (e.g. 7,7000-7100)",
+			Destination: &config.udp.ports,
 			DefaultText: "random",
 		}),
 
@@ -172,10 +371,11 @@ func init() {
 		}),
 
 		&cli.StringFlag{
-			Name:    "config",
-			Aliases: []string{"c"},
-			Value:   "",
-			Usage:   "Location of the configuration `file` in .yml format",
+			Name:        "config",
+			Aliases:     []string{"c"},
+			Value:       "",
+			Usage:       "Location of the configuration `file` in .yml format",
+			Destination: &config.file,
 		},
 	}
 
@@ -199,11 +399,12 @@ func init() {
 		Name:                  "Echo Server",
 		Version:               "v1.0.0",
 		Compiled:              time.Now(),
-		UsageText:             fmt.Sprintf("%s [global options]", app.name),
+		UsageText:             fmt.Sprintf("%s [global options] [command]", app.name),
 		HelpName:              app.name,
 		HideHelpCommand:       true,
-		Before:                altsrc.InitInputSourceWithContext(flags, altsrc.NewYamlSourceFromFlagFunc("config")),
+		Before:                altsrc.InitInputSourceWithContext(flags, newYamlSource("config")),
 		Flags:                 flags,
+		Commands:              []*cli.Command{newCertCommand()},
 		CustomAppHelpTemplate: helpTemplate,
 		Action: func(cCtx *cli.Context) error {
 			if len(os.Args) < 2 {
@@ -212,11 +413,19 @@ func init() {
 			}
 
 			if !cCtx.Bool("help") && !cCtx.Bool("version") {
+				config.server.hosts = cCtx.StringSlice("host")
+				config.https.certHosts = cCtx.StringSlice("cert-host")
+				config.tls.ciphers = cCtx.StringSlice("tls-cipher")
+				config.tls.curves = cCtx.StringSlice("tls-curve")
+				config.tls.alpn = cCtx.StringSlice("tls-alpn")
+				config.ws.subprotocols = cCtx.StringSlice("ws-subprotocol")
+
 				if err := config.init(); err != nil {
 					return err
 				}
 
 				if err := log.init(); err != nil {
+					config.removeAutoCert()
 					return err
 				}
 
@@ -239,3 +448,48 @@ func (q *quietBoolFlag) String() string {
 func (q *quietBoolFlag) GetDefaultText() string {
 	return ""
 }
+
+// yamlSource reads the options of the configuration file. The port options
+// accepting lists are string flags, so plain port numbers of the file are
+// converted to strings, and a single value of a repeatable option is read as
+// a list.
+type yamlSource struct {
+	altsrc.InputSourceContext
+}
+
+func newYamlSource(flagFileName string) func(cCtx *cli.Context) (altsrc.InputSourceContext, error) {
+	return func(cCtx *cli.Context) (altsrc.InputSourceContext, error) {
+		source, err := altsrc.NewYamlSourceFromFlagFunc(flagFileName)(cCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		return &yamlSource{source}, nil
+	}
+}
+
+func (y *yamlSource) String(name string) (string, error) {
+	value, err := y.InputSourceContext.String(name)
+	if err != nil {
+		if port, intErr := y.InputSourceContext.Int(name); intErr == nil {
+			return strconv.Itoa(port), nil
+		}
+	}
+
+	return value, err
+}
+
+func (y *yamlSource) StringSlice(name string) ([]string, error) {
+	values, err := y.InputSourceContext.StringSlice(name)
+	if err != nil {
+		if value, strErr := y.String(name); strErr == nil {
+			if len(value) == 0 {
+				return nil, nil
+			}
+
+			return []string{value}, nil
+		}
+	}
+
+	return values, err
+}
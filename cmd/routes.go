@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+)
+
+type route struct {
+	Path        string            `yaml:"path"`         // Path pattern (see path.Match)
+	Method      string            `yaml:"method"`       // HTTP method, empty or "*" matches any method
+	Status      int               `yaml:"status"`       // Response status code
+	Headers     map[string]string `yaml:"headers"`      // Response headers
+	Body        string            `yaml:"body"`         // Response body
+	BodyFile    string            `yaml:"body-file"`    // Path to file which contains response body
+	ContentType string            `yaml:"content-type"` // Content-Type header
+	Template    bool              `yaml:"template"`     // Render body as a Go template
+
+	template *template.Template
+}
+
+func (r *route) init() error {
+	if len(r.Path) == 0 {
+		return fmt.Errorf("route path must be specified")
+	}
+
+	if _, err := path.Match(r.Path, "/"); err != nil {
+		return fmt.Errorf("invalid route path pattern %q: %v", r.Path, err)
+	}
+
+	r.Method = strings.ToUpper(r.Method)
+
+	// Informational (1xx) codes cannot be the final status of a response.
+	if r.Status == 0 {
+		r.Status = http.StatusOK
+	} else if r.Status < 200 || r.Status > 999 {
+		return fmt.Errorf("invalid status code for route %s: %v", r.Path, r.Status)
+	}
+
+	if len(r.BodyFile) > 0 {
+		if !fileExists(r.BodyFile) {
+			return fmt.Errorf("body file of route %s specified but not found: %s", r.Path, r.BodyFile)
+		}
+
+		body, err := os.ReadFile(r.BodyFile)
+		if err != nil {
+			return err
+		}
+
+		r.Body = string(body)
+	}
+
+	if r.Template {
+		var err error
+		if r.template, err = parseTemplate("route "+r.Path, r.Body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *route) match(req *http.Request) bool {
+	if len(r.Method) > 0 && r.Method != "*" && r.Method != req.Method {
+		return false
+	}
+
+	matched, _ := path.Match(r.Path, req.URL.Path)
+
+	return matched
+}
+
+func (r *route) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body := []byte(r.Body)
+	if r.template != nil {
+		var err error
+		if body, err = renderTemplate(r.template, req); err != nil {
+			log.error.Println(err)
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	for name, value := range r.Headers {
+		w.Header().Set(name, value)
+	}
+
+	if len(r.ContentType) > 0 {
+		w.Header().Set("Content-Type", r.ContentType)
+	}
+
+	w.WriteHeader(r.Status)
+	w.Write(body)
+}
+
+// routes serves requests matching one of the configured routes, every other
+// request is passed to next.
+func (s *appServer) routes(next http.Handler) http.Handler {
+	if len(config.routes) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for _, r := range config.routes {
+			if r.match(req) {
+				log.request(r).ServeHTTP(w, req)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
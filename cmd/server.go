@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,69 +10,95 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync/atomic"
 	"syscall"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	tlsHandshakeTimeout = 10 * time.Second // Timeout of the TLS TCP echo handshake
+	shutdownTimeout     = 5 * time.Second  // Timeout of the graceful shutdown of the HTTP(S) servers
+
+	streamBufferSize   = 1024       // Read buffer of the stream echo connections
+	datagramBufferSize = 64 * 1024  // Read buffer of the UDP echo, larger than any UDP datagram
+	messageBufferSize  = 256 * 1024 // Read buffer of the unixpacket and unixgram echo (see bufferSize)
 )
 
 type appServer struct {
-	http        http.Server
-	https       http.Server
-	udpConn     *net.UDPConn
-	tcpListener *net.TCPListener
-	udpClosed   bool
-	tcpClosed   bool
+	listeners   []listener
+	ctx         context.Context    // Base context of the HTTP(S) requests, canceled by stop
+	cancel      context.CancelFunc // Cancels ctx
+	handler     http.Handler
+	altSvcValue string // Alt-Svc header of the HTTPS responses, set once the servers listen
+	upgrader    websocket.Upgrader
+	sse         sseBroker
 	idle        chan struct{}
 	errors      chan error
 }
 
-func (s *appServer) start() {
+// start starts the servers and blocks until they are stopped. It returns an
+// error if one of the servers could not be started.
+func (s *appServer) start() error {
 	s.errors = make(chan error)
 	s.idle = make(chan struct{})
+	s.ctx, s.cancel = context.WithCancel(context.Background())
 
-	if config.http.enabled || config.https.enabled {
+	if config.httpEnabled() {
 		s.handleFunctions()
 	}
 
-	if config.http.enabled {
-		s.http = http.Server{
-			Addr:     config.http.address,
-			ErrorLog: log.error,
-		}
+	for _, l := range config.listeners {
+		s.listeners = append(s.listeners, s.newListeners(l)...)
+	}
 
-		go func() {
-			log.info.Printf("HTTP server listening on %v\n", config.http.address)
-			s.errors <- s.http.ListenAndServe()
-		}()
+	if config.tlsEnabled() {
+		config.https.certs.logCertificates()
+		logTLSPolicy(config.https.tlsConfig)
 	}
 
-	if config.https.enabled {
-		s.https = http.Server{
-			Addr:     config.https.address,
-			ErrorLog: log.error,
+	for _, l := range s.listeners {
+		if err := l.listen(); err != nil {
+			s.stop()
+			return fmt.Errorf("could not start %s: %v", l, err)
 		}
 
-		go func() {
-			log.info.Printf("HTTPS server listening on %v\n", config.https.address)
-			s.errors <- s.https.ListenAndServeTLS(config.https.cert, config.https.key)
-		}()
+		log.info.Printf("%s listening on %v\n", l, l.addr())
 	}
 
-	if config.tcp.enabled {
-		go func() {
-			s.tcpEcho()
-		}()
+	s.altSvcValue = s.altSvcHeader()
+
+	for _, l := range s.listeners {
+		go func(l listener) {
+			if err := l.serve(); err != nil {
+				s.errors <- fmt.Errorf("%s on %v: %v", l, l.addr(), err)
+			}
+		}(l)
 	}
 
-	if config.udp.enabled {
-		go func() {
-			s.udpEcho()
-		}()
+	if config.tlsEnabled() {
+		if config.https.reloadInterval > 0 {
+			go config.https.certs.watch(config.https.reloadInterval, s.idle)
+		}
+
+		go s.reload()
 	}
 
 	go s.close()
 
-	log.error.Println(<-s.errors)
+	// The servers can still report errors while shutting down, so s.errors is
+	// never closed.
+	select {
+	case err := <-s.errors:
+		log.error.Println(err)
+	case <-s.idle:
+	}
 
 	<-s.idle
+
+	return nil
 }
 
 func (s *appServer) close() {
@@ -81,122 +108,290 @@ func (s *appServer) close() {
 	s.stop()
 }
 
-func (s *appServer) stop() {
-	if config.http.enabled {
-		if err := s.http.Shutdown(context.Background()); err != nil {
-			// Error from closing listeners, or context timeout:
-			log.error.Printf("HTTP server shutdown error: %v\n", err)
-		} else {
-			log.info.Println("HTTP server shutdown")
+// reload reloads the certificates on SIGHUP.
+func (s *appServer) reload() {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	for {
+		select {
+		case <-s.idle:
+			return
+		case <-sighup:
+			log.info.Println("SIGHUP received, reloading certificates")
+			config.https.certs.reload()
 		}
 	}
+}
+
+// stop stops the servers which were opened. The requests are canceled first,
+// so that long-running handlers (e.g. SSE streams) do not block the shutdown.
+func (s *appServer) stop() {
+	s.cancel()
+
+	for _, l := range s.listeners {
+		if l.addr() == nil {
+			continue
+		}
 
-	if config.https.enabled {
-		if err := s.https.Shutdown(context.Background()); err != nil {
-			// Error from closing listeners, or context timeout:
-			log.error.Printf("HTTPS server shutdown error: %v\n", err)
+		if err := l.close(); err != nil {
+			log.error.Printf("%s on %v shutdown error: %v\n", l, l.addr(), err)
+		} else if stats, ok := listenerStats(l); ok {
+			log.info.Printf("%s on %v shutdown (%s)\n", l, l.addr(), stats)
 		} else {
-			log.info.Println("HTTPS server shutdown")
+			log.info.Printf("%s on %v shutdown\n", l, l.addr())
 		}
+	}
 
-		if config.https.autoCert {
-			if err := os.Remove(config.https.cert); err != nil {
-				log.error.Printf("error while removing cert file: %v\n", err)
-			}
+	if err := config.removeAutoCert(); err != nil {
+		log.error.Println(err)
+	}
+
+	log.close()
+
+	close(s.idle)
+}
 
-			if err := os.Remove(config.https.key); err != nil {
-				log.error.Printf("error while removing key file: %v\n", err)
+func (s *appServer) handleFunctions() {
+	s.handle("/", func(w http.ResponseWriter, req *http.Request) {
+		content := []byte(config.content.content)
+		if config.content.template != nil {
+			var err error
+			if content, err = renderTemplate(config.content.template, req); err != nil {
+				log.error.Println(err)
+				writeError(w, http.StatusInternalServerError, err)
+				return
 			}
 		}
-	}
 
-	if config.udp.enabled {
-		log.info.Println("UDP echo server shutdown")
+		if config.content.addContentType {
+			w.Header().Set("Content-Type", config.content.contentType)
+		}
+
+		if config.content.compress {
+			if encoding := negotiateEncoding(req.Header.Get("Accept-Encoding")); len(encoding) > 0 {
+				writeCompressed(w, http.StatusOK, encoding, content)
+				return
+			}
 
-		s.udpClosed = true
-		if err := s.udpConn.Close(); err != nil {
-			log.error.Printf("udpConn.Close() error: %s\n", err)
+			w.Header().Add("Vary", "Accept-Encoding")
 		}
-	}
 
-	if config.tcp.enabled {
-		log.info.Println("TCP echo server shutdown")
+		w.Write(content)
+	})
+
+	s.handle("/headers", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
 
-		s.tcpClosed = true
-		if err := s.tcpListener.Close(); err != nil {
-			log.error.Printf("TCPListener.Close() error: %s\n", err)
+		if reqHeadersBytes, err := json.Marshal(req.Header); err != nil {
+			log.error.Println("could not marshal request headers:", err)
+
+			w.Write([]byte(fmt.Sprintf(`{"error": "%s"}`, err)))
+		} else {
+			w.Write([]byte(reqHeadersBytes))
 		}
-	}
+	})
 
-	log.close()
+	s.handle("/echo", s.handleEcho)
+	s.handle("/anything", s.handleEcho)
+	s.handle("/anything/", s.handleEcho)
 
-	close(s.errors)
-	close(s.idle)
+	s.handle("/status/", s.handleStatus)
+	s.handle("/delay/", s.handleDelay)
+	s.handle("/redirect/", s.handleRedirect)
+	s.handle("/redirect-to", s.handleRedirectTo)
+	s.handle("/relative-redirect/", s.handleRelativeRedirect)
+	s.handle("/absolute-redirect/", s.handleAbsoluteRedirect)
+
+	s.handle("/bytes/", s.handleBytes)
+	s.handle("/stream/", s.handleStream)
+	s.handle("/drip", s.handleDrip)
+	s.handle("/range/", s.handleRange)
+	s.handle("/chunked/", s.handleChunked)
+
+	s.handle("/gzip", s.handleCompressed("gzip"))
+	s.handle("/deflate", s.handleCompressed("deflate"))
+	s.handle("/brotli", s.handleCompressed("br"))
+
+	s.newUpgrader()
+	s.handle("/ws", s.handleWebSocket)
+
+	s.handle("/sse", s.handleSSE)
+	s.handle("/sse/publish", s.handleSSEPublish)
+
+	s.handle("/cookies", s.handleCookies)
+	s.handle("/cookies/set", s.handleSetCookies)
+	s.handle("/cookies/delete", s.handleDeleteCookies)
+
+	s.handle("/basic-auth/", s.handleBasicAuth)
+	s.handle("/bearer", s.handleBearer)
+	s.handle("/digest-auth/", s.handleDigestAuth)
+
+	s.handle("/cache", s.handleCache)
+	s.handle("/cache/", s.handleCacheControl)
+
+	s.handle("/tls", s.handleTLS)
+
+	s.handler = s.altSvc(fingerprintHeaders(s.routes(http.DefaultServeMux)))
 }
 
-func (s *appServer) handleFunctions() {
-	http.Handle("/", log.request(http.HandlerFunc(
-		func(w http.ResponseWriter, req *http.Request) {
-			if config.content.addContentType {
-				w.Header().Set("Content-Type", config.content.contentType)
-			}
+func (s *appServer) handle(pattern string, handler http.HandlerFunc) {
+	http.Handle(pattern, log.request(handler))
+}
+
+// echoListener is an echo server counting its traffic.
+type echoListener interface {
+	stats() string // Statistics of the server
+}
+
+// listenerStats returns the statistics of an echo server.
+func listenerStats(l listener) (string, bool) {
+	if s, ok := l.(*socketListener); ok {
+		l = s.listener
+	}
+
+	if e, ok := l.(echoListener); ok {
+		return e.stats(), true
+	}
 
-			w.Write([]byte(config.content.content))
-		}),
-	))
+	return "", false
+}
 
-	http.Handle("/headers", log.request(http.HandlerFunc(
-		func(w http.ResponseWriter, req *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
+// echoStats counts the traffic of an echo server.
+type echoStats struct {
+	connections  atomic.Int64
+	packets      atomic.Int64
+	bytesRead    atomic.Int64
+	bytesWritten atomic.Int64
+}
 
-			if reqHeadersBytes, err := json.Marshal(req.Header); err != nil {
-				log.error.Println("could not marshal request headers:", err)
+func (e *echoStats) read(n int) {
+	e.packets.Add(1)
+	e.bytesRead.Add(int64(n))
+}
 
-				w.Write([]byte(fmt.Sprintf(`{"error": "%s"}`, err)))
-			} else {
-				w.Write([]byte(reqHeadersBytes))
-			}
-		}),
-	))
+func (e *echoStats) written(n int) {
+	e.bytesWritten.Add(int64(n))
 }
 
-func (s *appServer) tcpEcho() {
-	var err error
-	s.tcpListener, err = net.ListenTCP("tcp", &config.tcp.address)
+// tcpEchoListener echoes the bytes of stream connections (tcp, unix or
+// unixpacket). With tls the decrypted bytes are echoed back over TLS, using
+// the certificates and TLS configuration of the HTTPS server.
+type tcpEchoListener struct {
+	network  string
+	address  string
+	tls      bool
+	listener net.Listener
+	closed   atomic.Bool
+	counter  echoStats
+}
+
+func (l *tcpEchoListener) listen() error {
+	listener, err := net.Listen(l.network, l.address)
 	if err != nil {
-		log.error.Printf("net.ListenTCP() error: %s\n", err)
-		return
+		return err
+	}
+
+	if l.tls {
+		listener = tls.NewListener(&helloListener{listener}, config.https.tlsConfig)
 	}
 
-	log.info.Printf("TCP echo server listening on %v\n", s.tcpListener.Addr().String())
+	l.listener = listener
 
+	return nil
+}
+
+func (l *tcpEchoListener) serve() error {
 	for {
-		conn, err := s.tcpListener.Accept()
+		conn, err := l.listener.Accept()
 		if err != nil {
 			if errors.Is(err, net.ErrClosed) {
-				if s.tcpClosed {
-					return
+				if l.closed.Load() {
+					return nil
 				}
 
-				log.error.Fatalf("net.ReadFromUDP() error: %s\n", err)
+				return err
 			}
 
-			log.error.Printf("TCPListener.Accept() error: %s\n", err)
+			log.error.Printf("Listener.Accept() error: %s\n", err)
+		} else if l.tls {
+			go handleTLSConnection(conn.(*tls.Conn), &l.counter)
 		} else {
-			go s.handleTCPConnection(conn)
+			go handleTCPConnection(conn, networkName(l.network), l.bufferSize(), &l.counter)
 		}
 	}
 }
 
-func (s *appServer) handleTCPConnection(conn net.Conn) {
+func (l *tcpEchoListener) close() error {
+	l.closed.Store(true)
+
+	return l.listener.Close()
+}
+
+func (l *tcpEchoListener) addr() net.Addr {
+	if l.listener == nil {
+		return nil
+	}
+
+	return l.listener.Addr()
+}
+
+func (l *tcpEchoListener) stats() string {
+	return fmt.Sprintf("connections: %d, packets: %d, read: %d bytes, written: %d bytes",
+		l.counter.connections.Load(), l.counter.packets.Load(), l.counter.bytesRead.Load(), l.counter.bytesWritten.Load())
+}
+
+// bufferSize returns the read buffer size of the connections. A seqpacket read
+// returns one message and discards the part not fitting into the buffer, so
+// the buffer must hold the largest message, which is limited by the socket
+// send buffer (about 208 KiB by default on Linux).
+func (l *tcpEchoListener) bufferSize() int {
+	if l.network == "unixpacket" {
+		return messageBufferSize
+	}
+
+	return streamBufferSize
+}
+
+func (l *tcpEchoListener) String() string {
+	if l.tls {
+		return "TLS " + networkName(l.network) + " echo server"
+	}
+
+	return networkName(l.network) + " echo server"
+}
+
+func handleTLSConnection(conn *tls.Conn, counter *echoStats) {
+	remoteAddr := conn.RemoteAddr().String()
+
+	conn.SetDeadline(time.Now().Add(tlsHandshakeTimeout))
+	if err := conn.Handshake(); err != nil {
+		log.error.Printf("%s - TLS handshake error: %s\n", remoteAddr, err)
+		conn.Close()
+		return
+	}
+	conn.SetDeadline(time.Time{})
+
+	details := handshakeDetails(conn.ConnectionState())
+	if fp := connFingerprint(conn); fp != nil {
+		details = append(details, "JA3: "+fp.JA3Hash, "JA4: "+fp.JA4)
+	}
+
+	handleTCPConnection(conn, "TLS", streamBufferSize, counter, details...)
+}
+
+// handleTCPConnection echoes the bytes read from conn until it is closed.
+func handleTCPConnection(conn net.Conn, network string, bufferSize int, counter *echoStats, details ...string) {
 	remoteAddr := conn.RemoteAddr().String()
-	log.connection(true, remoteAddr)
+	log.connection(true, network, remoteAddr, details...)
+	counter.connections.Add(1)
 
 	defer conn.Close()
-	defer log.connection(false, remoteAddr)
+	defer log.connection(false, network, remoteAddr)
 
+	buf := make([]byte, bufferSize)
 	for {
-		buf := make([]byte, 1024)
 		n, err := conn.Read(buf)
 		if err != nil {
 			log.error.Printf("net.Read() error: %s\n", err)
@@ -207,54 +402,124 @@ func (s *appServer) handleTCPConnection(conn net.Conn) {
 			return
 		}
 
-		log.packet("read", "TCP", n, buf[:n], remoteAddr)
+		log.packet("read", network, n, buf[:n], remoteAddr)
+		counter.read(n)
 
 		wn, werr := conn.Write(buf[:n])
-		if err != nil {
+		counter.written(wn)
+		if werr != nil {
 			log.error.Printf("net.Write() error: %s\n", werr)
 		} else {
-			log.packet("write", "TCP", wn, []byte{}, remoteAddr)
+			log.packet("write", network, wn, []byte{}, remoteAddr)
 		}
 	}
 }
 
-func (s *appServer) udpEcho() {
-	var err error
-	s.udpConn, err = net.ListenUDP("udp", &config.udp.address)
-	if err != nil {
-		log.error.Fatalf("net.ListenUDP() error: %s\n", err)
-		return
+// udpEchoListener echoes the datagrams back to their sender (udp or unixgram).
+type udpEchoListener struct {
+	network string
+	address string
+	conn    net.PacketConn
+	closed  atomic.Bool
+	counter echoStats
+}
+
+func (l *udpEchoListener) listen() (err error) {
+	l.conn, err = net.ListenPacket(l.network, l.address)
+
+	return
+}
+
+// bufferSize returns the read buffer size of the datagrams. The part of a
+// datagram not fitting into the buffer is discarded, so the buffer must hold
+// the largest datagram (see tcpEchoListener.bufferSize for unixgram).
+func (l *udpEchoListener) bufferSize() int {
+	if l.network == "unixgram" {
+		return messageBufferSize
 	}
 
-	log.info.Printf("UDP echo server listening on %v\n", s.udpConn.LocalAddr())
+	return datagramBufferSize
+}
 
-	buf := make([]byte, 4096)
+func (l *udpEchoListener) serve() error {
+	buf := make([]byte, l.bufferSize())
 	for {
-		n, addr, err := s.udpConn.ReadFromUDP(buf)
-		remoteAddr := addr.String()
+		n, addr, err := l.conn.ReadFrom(buf)
 		if err != nil {
 			if errors.Is(err, net.ErrClosed) {
-				if s.udpClosed {
-					return
+				if l.closed.Load() {
+					return nil
 				}
 
-				log.error.Fatalf("net.ReadFromUDP() error: %s\n", err)
+				return err
 			}
 
-			log.error.Printf("net.ReadFromUDP() error: %s\n", err)
+			log.error.Printf("net.ReadFrom() error: %s\n", err)
+
+			continue
+		}
 
+		// Datagrams of unbound unixgram sockets have no address to reply to.
+		if addr == nil {
+			log.error.Printf("%s echo server: datagram without sender address dropped\n", networkName(l.network))
 			continue
 		}
 
+		remoteAddr := addr.String()
+		network := networkName(l.network)
+
+		if n == len(buf) {
+			log.error.Printf("%s - %s echo server: datagram filling the %d byte buffer may be truncated\n", remoteAddr, network, n)
+		}
+
 		if n > 0 {
-			log.packet("read", "UDP", n, buf[:n], remoteAddr)
+			log.packet("read", network, n, buf[:n], remoteAddr)
+			l.counter.read(n)
 
-			wn, werr := s.udpConn.WriteTo(buf[:n], addr)
-			if err != nil {
+			wn, werr := l.conn.WriteTo(buf[:n], addr)
+			l.counter.written(wn)
+			if werr != nil {
 				log.error.Printf("net.WriteTo() error: %s\n", werr)
 			} else {
-				log.packet("write", "UDP", wn, nil, remoteAddr)
+				log.packet("write", network, wn, nil, remoteAddr)
 			}
 		}
 	}
 }
+
+func (l *udpEchoListener) close() error {
+	l.closed.Store(true)
+
+	return l.conn.Close()
+}
+
+func (l *udpEchoListener) addr() net.Addr {
+	if l.conn == nil {
+		return nil
+	}
+
+	return l.conn.LocalAddr()
+}
+
+func (l *udpEchoListener) stats() string {
+	return fmt.Sprintf("packets: %d, read: %d bytes, written: %d bytes",
+		l.counter.packets.Load(), l.counter.bytesRead.Load(), l.counter.bytesWritten.Load())
+}
+
+func (l *udpEchoListener) String() string {
+	return networkName(l.network) + " echo server"
+}
+
+// networkName returns the name of a network used in the log.
+func networkName(network string) string {
+	switch network {
+	case "unix":
+		return "Unix"
+	case "unixpacket":
+		return "Unix seqpacket"
+	case "unixgram":
+		return "Unix datagram"
+	}
+
+	return strings.ToUpper(network)
+}
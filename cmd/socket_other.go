@@ -0,0 +1,8 @@
+//go:build !unix
+
+package cmd
+
+// listenPrivate calls listen, the umask is not supported on this platform.
+func listenPrivate(listen func() error) error {
+	return listen()
+}
@@ -0,0 +1,16 @@
+//go:build unix
+
+package cmd
+
+import "syscall"
+
+// listenPrivate calls listen with a umask denying access to the group and
+// others, so that a new socket file is not accessible before its mode is set.
+// The umask is process-wide, so it must not run concurrently with code
+// creating files.
+func listenPrivate(listen func() error) error {
+	mask := syscall.Umask(0o077)
+	defer syscall.Umask(mask)
+
+	return listen()
+}
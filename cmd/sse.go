@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+// sseBroker distributes the published events to the connected SSE clients.
+type sseBroker struct {
+	mu      sync.Mutex
+	clients map[chan sseEvent]struct{}
+}
+
+func (b *sseBroker) subscribe() chan sseEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.clients == nil {
+		b.clients = make(map[chan sseEvent]struct{})
+	}
+
+	ch := make(chan sseEvent, 16)
+	b.clients[ch] = struct{}{}
+
+	return ch
+}
+
+func (b *sseBroker) unsubscribe(ch chan sseEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.clients, ch)
+}
+
+// publish sends the event to every connected client and returns the number
+// of clients it was delivered to. Slow clients with a full buffer miss the
+// event.
+func (b *sseBroker) publish(event sseEvent) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delivered := 0
+	for ch := range b.clients {
+		select {
+		case ch <- event:
+			delivered++
+		default:
+		}
+	}
+
+	return delivered
+}
+
+// handleSSE streams an event every interval. The stream can be resumed with
+// the Last-Event-ID header, events published to /sse/publish are forwarded
+// to every connected client.
+func (s *appServer) handleSSE(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	interval := config.sse.interval
+	if len(query.Get("interval")) > 0 {
+		var err error
+		if interval, err = durationParam(query.Get("interval"), 0); err != nil || interval <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid interval: %s", query.Get("interval")))
+			return
+		}
+	}
+
+	count := 0
+	if len(query.Get("count")) > 0 {
+		var err error
+		if count, err = strconv.Atoi(query.Get("count")); err != nil || count < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid count: %s", query.Get("count")))
+			return
+		}
+	}
+
+	retry := config.sse.retry
+	if len(query.Get("retry")) > 0 {
+		var err error
+		if retry, err = strconv.Atoi(query.Get("retry")); err != nil || retry < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid retry: %s", query.Get("retry")))
+			return
+		}
+	}
+
+	name := query.Get("event")
+
+	id := 0
+	if lastEventID := req.Header.Get("Last-Event-ID"); len(lastEventID) > 0 {
+		if last, err := strconv.Atoi(lastEventID); err == nil {
+			id = last + 1
+		}
+	}
+
+	events := s.sse.subscribe()
+	defer s.sse.unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	if retry > 0 {
+		fmt.Fprintf(w, "retry: %d\n\n", retry)
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for sent := 0; count == 0 || sent < count; sent++ {
+		var event sseEvent
+
+		select {
+		case <-req.Context().Done():
+			return
+		case event = <-events:
+		case t := <-ticker.C:
+			event = sseEvent{
+				name: name,
+				data: fmt.Sprintf(`{"id": %d, "time": "%s"}`, id, t.Format(time.RFC3339Nano)),
+			}
+		}
+
+		if err := writeSSEEvent(w, id, event); err != nil {
+			return
+		}
+		flusher.Flush()
+
+		id++
+	}
+}
+
+// handleSSEPublish sends the request body as an event to every connected SSE
+// client. The event name can be set with the event query parameter.
+func (s *appServer) handleSSEPublish(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	clients := s.sse.publish(sseEvent{
+		name: req.URL.Query().Get("event"),
+		data: string(body),
+	})
+
+	writeJSON(w, http.StatusOK, map[string]int{"clients": clients})
+}
+
+func writeSSEEvent(w http.ResponseWriter, id int, event sseEvent) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "id: %d\n", id)
+	if len(event.name) > 0 {
+		fmt.Fprintf(&b, "event: %s\n", event.name)
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(event.data, "\r\n", "\n"), "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+
+	_, err := w.Write([]byte(b.String()))
+
+	return err
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxDelay = 10 * time.Second // Maximum delay of the /delay endpoint
+
+type weightedStatus struct {
+	code   int
+	weight float64
+}
+
+// handleStatus responds with the status code given in the path. Multiple
+// comma separated codes can be given with optional weights (e.g.
+// "200:0.9,500:0.1"), in which case one of them is chosen randomly.
+func (s *appServer) handleStatus(w http.ResponseWriter, req *http.Request) {
+	codes, err := parseStatusCodes(pathParam(req, "/status/"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	code, err := chooseStatus(codes)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	switch {
+	case code >= 300 && code < 400:
+		w.Header().Set("Location", "/redirect/1")
+	case code == http.StatusUnauthorized:
+		w.Header().Set("WWW-Authenticate", `Basic realm="Fake Realm"`)
+	case code == http.StatusProxyAuthRequired:
+		w.Header().Set("Proxy-Authenticate", `Basic realm="Fake Realm"`)
+	}
+
+	w.WriteHeader(code)
+}
+
+func parseStatusCodes(value string) ([]weightedStatus, error) {
+	if len(value) == 0 {
+		return nil, errors.New("status code must be specified")
+	}
+
+	var codes []weightedStatus
+	for _, item := range strings.Split(value, ",") {
+		codeStr, weightStr, hasWeight := strings.Cut(item, ":")
+
+		code, err := strconv.Atoi(codeStr)
+		// Informational (1xx) codes cannot be the final status of a response.
+		if err != nil || code < 200 || code > 999 {
+			return nil, fmt.Errorf("invalid status code: %s", codeStr)
+		}
+
+		weight := 1.0
+		if hasWeight {
+			if weight, err = strconv.ParseFloat(weightStr, 64); err != nil || weight < 0 {
+				return nil, fmt.Errorf("invalid weight of status code %d: %s", code, weightStr)
+			}
+		}
+
+		codes = append(codes, weightedStatus{code: code, weight: weight})
+	}
+
+	return codes, nil
+}
+
+func chooseStatus(codes []weightedStatus) (int, error) {
+	var total float64
+	for _, c := range codes {
+		total += c.weight
+	}
+
+	r, err := randFloat()
+	if err != nil {
+		return 0, err
+	}
+
+	r *= total
+	for _, c := range codes {
+		if r < c.weight {
+			return c.code, nil
+		}
+
+		r -= c.weight
+	}
+
+	return codes[len(codes)-1].code, nil
+}
+
+// handleDelay echoes the request after the number of seconds given in the
+// path (at most maxDelay).
+func (s *appServer) handleDelay(w http.ResponseWriter, req *http.Request) {
+	seconds, err := strconv.ParseFloat(pathParam(req, "/delay/"), 64)
+	if err != nil || seconds < 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid delay: %s", pathParam(req, "/delay/")))
+		return
+	}
+
+	// Limited before the conversion, which overflows for huge values.
+	delay := maxDelay
+	if seconds < maxDelay.Seconds() {
+		delay = time.Duration(seconds * float64(time.Second))
+	}
+
+	if !sleep(req, delay) {
+		return
+	}
+
+	s.handleEcho(w, req)
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	maxBytes    = 10 << 20         // Maximum number of bytes returned by /bytes and /range
+	maxLines    = 100              // Maximum number of lines returned by /stream
+	maxChunks   = 100              // Maximum number of chunks returned by /chunked
+	maxDuration = 60 * time.Second // Maximum duration of /drip
+)
+
+// handleBytes returns n random bytes. If the seed query parameter is given,
+// the same seed always generates the same data.
+func (s *appServer) handleBytes(w http.ResponseWriter, req *http.Request) {
+	n, err := sizeParam(pathParam(req, "/bytes/"), maxBytes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	data := make([]byte, n)
+	if seedStr := req.URL.Query().Get("seed"); len(seedStr) > 0 {
+		seed, err := strconv.ParseInt(seedStr, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid seed: %s", seedStr))
+			return
+		}
+
+		rand.New(rand.NewSource(seed)).Read(data)
+	} else if _, err := crand.Read(data); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(n))
+	w.Write(data)
+}
+
+// handleStream returns the request echo n times as JSON lines, flushing the
+// response after each line.
+func (s *appServer) handleStream(w http.ResponseWriter, req *http.Request) {
+	n, err := sizeParam(pathParam(req, "/stream/"), maxLines)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	echo, err := newRequestEcho(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+
+	for i := 0; i < n; i++ {
+		line, err := json.Marshal(struct {
+			ID int `json:"id"`
+			*requestEcho
+		}{i, echo})
+		if err != nil {
+			log.error.Println("could not marshal response:", err)
+			return
+		}
+
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return
+		}
+
+		flush(w)
+	}
+}
+
+// handleDrip writes numbytes bytes evenly over duration seconds, after an
+// initial delay of delay seconds.
+func (s *appServer) handleDrip(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	duration, err := durationParam(query.Get("duration"), 2*time.Second)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	delay, err := durationParam(query.Get("delay"), 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	numBytes := 10
+	if len(query.Get("numbytes")) > 0 {
+		if numBytes, err = sizeParam(query.Get("numbytes"), maxBytes); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	code := http.StatusOK
+	if len(query.Get("code")) > 0 {
+		// Informational (1xx) codes cannot be the final status of a response.
+		if code, err = strconv.Atoi(query.Get("code")); err != nil || code < 200 || code > 999 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid status code: %s", query.Get("code")))
+			return
+		}
+	}
+
+	if !sleep(req, delay) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(numBytes))
+	w.WriteHeader(code)
+	flush(w)
+
+	var interval time.Duration
+	if numBytes > 1 {
+		interval = duration / time.Duration(numBytes-1)
+	}
+
+	for i := 0; i < numBytes; i++ {
+		if i > 0 && !sleep(req, interval) {
+			return
+		}
+
+		if _, err := w.Write([]byte{'*'}); err != nil {
+			return
+		}
+
+		flush(w)
+	}
+}
+
+// handleRange returns n bytes of alphabetic data and honors the Range and
+// If-Range request headers.
+func (s *appServer) handleRange(w http.ResponseWriter, req *http.Request) {
+	n, err := sizeParam(pathParam(req, "/range/"), maxBytes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte('a' + i%26)
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("ETag", fmt.Sprintf(`"range%d"`, n))
+
+	http.ServeContent(w, req, "", time.Time{}, bytes.NewReader(data))
+}
+
+// handleChunked sends n chunks using chunked transfer encoding, followed by
+// trailers containing the number of chunks and the SHA-256 digest of the body.
+func (s *appServer) handleChunked(w http.ResponseWriter, req *http.Request) {
+	n, err := sizeParam(pathParam(req, "/chunked/"), maxChunks)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+	w.Header().Set("Trailer", "X-Chunk-Count, X-Content-SHA256")
+	w.WriteHeader(http.StatusOK)
+
+	hash := sha256.New()
+	for i := 0; i < n; i++ {
+		chunk := []byte(fmt.Sprintf("chunk %d\n", i))
+		hash.Write(chunk)
+
+		if _, err := w.Write(chunk); err != nil {
+			return
+		}
+
+		flush(w)
+	}
+
+	w.Header().Set("X-Chunk-Count", strconv.Itoa(n))
+	w.Header().Set("X-Content-SHA256", hex.EncodeToString(hash.Sum(nil)))
+}
+
+func sizeParam(value string, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid number: %s", value)
+	} else if n > max {
+		return 0, fmt.Errorf("number too large: %d (max. %d)", n, max)
+	}
+
+	return n, nil
+}
+
+// durationParam parses a duration given in seconds, returning def if value is
+// empty.
+func durationParam(value string, def time.Duration) (time.Duration, error) {
+	if len(value) == 0 {
+		return def, nil
+	}
+
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return 0, fmt.Errorf("invalid duration: %s", value)
+	}
+
+	// Checked before the conversion, which overflows for huge values.
+	if seconds > maxDuration.Seconds() {
+		return 0, fmt.Errorf("duration too long: %s (max. %v)", value, maxDuration)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// sleep waits for d, returning false if the request is cancelled meanwhile.
+func sleep(req *http.Request, d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-req.Context().Done():
+		return false
+	}
+}
+
+func flush(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
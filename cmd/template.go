@@ -3,12 +3,15 @@ package cmd
 var helpTemplate = `{{$v := offset .Name 6}}{{wrap .Name 3}}
 
 Usage:
-   {{.HelpName}} [options] {{if .Description}}
+   {{.HelpName}} [options] {{if .VisibleCommands}}[command] {{end}}{{if .Description}}
 
 Description:
    {{wrap .Description 3}}{{end}}
 
-Options:{{range .VisibleFlagCategories}}
+{{if .VisibleCommands}}Commands:{{range .VisibleCommands}}
+   {{join .Names ", "}}{{"\t"}}{{.Usage}}{{end}}
+
+{{end}}Options:{{range .VisibleFlagCategories}}
    {{if .Name}}{{.Name}}
    {{end}}{{range .Flags}}{{.}}
    {{end}}{{end}}
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+type tlsInfo struct {
+	Version            string     `json:"version"`
+	CipherSuite        string     `json:"cipher_suite"`
+	ServerName         string     `json:"server_name"`
+	ALPN               string     `json:"alpn"`
+	Resumed            bool       `json:"resumed"`
+	ClientIdentity     string     `json:"client_identity"`
+	ClientVerified     bool       `json:"client_verified"`
+	ClientCertificates []certInfo `json:"client_certificates"`
+
+	*clientFingerprint
+}
+
+type certInfo struct {
+	Subject           string    `json:"subject"`
+	Issuer            string    `json:"issuer"`
+	SerialNumber      string    `json:"serial_number"`
+	DNSNames          []string  `json:"dns_names"`
+	IPAddresses       []string  `json:"ip_addresses"`
+	EmailAddresses    []string  `json:"email_addresses"`
+	URIs              []string  `json:"uris"`
+	NotBefore         time.Time `json:"not_before"`
+	NotAfter          time.Time `json:"not_after"`
+	IsCA              bool      `json:"is_ca"`
+	SHA1Fingerprint   string    `json:"sha1_fingerprint"`
+	SHA256Fingerprint string    `json:"sha256_fingerprint"`
+}
+
+func newTLSInfo(req *http.Request) *tlsInfo {
+	state := req.TLS
+	if state == nil {
+		return nil
+	}
+
+	info := &tlsInfo{
+		Version:            tlsVersionName(state.Version),
+		CipherSuite:        tls.CipherSuiteName(state.CipherSuite),
+		ServerName:         state.ServerName,
+		ALPN:               state.NegotiatedProtocol,
+		Resumed:            state.DidResume,
+		ClientIdentity:     clientIdentity(state),
+		ClientVerified:     len(state.VerifiedChains) > 0,
+		ClientCertificates: []certInfo{},
+		clientFingerprint:  requestFingerprint(req),
+	}
+
+	for _, cert := range state.PeerCertificates {
+		info.ClientCertificates = append(info.ClientCertificates, newCertInfo(cert))
+	}
+
+	return info
+}
+
+func newCertInfo(cert *x509.Certificate) certInfo {
+	info := certInfo{
+		Subject:           cert.Subject.String(),
+		Issuer:            cert.Issuer.String(),
+		SerialNumber:      serialString(cert.SerialNumber),
+		DNSNames:          cert.DNSNames,
+		EmailAddresses:    cert.EmailAddresses,
+		NotBefore:         cert.NotBefore,
+		NotAfter:          cert.NotAfter,
+		IsCA:              cert.IsCA,
+		SHA1Fingerprint:   fingerprint(sha1Sum(cert.Raw)),
+		SHA256Fingerprint: fingerprint(sha256Sum(cert.Raw)),
+	}
+
+	for _, ip := range cert.IPAddresses {
+		info.IPAddresses = append(info.IPAddresses, ip.String())
+	}
+
+	for _, uri := range cert.URIs {
+		info.URIs = append(info.URIs, uri.String())
+	}
+
+	return info
+}
+
+// clientIdentity returns the subject of the client certificate, or an empty
+// string if the client did not present one.
+func clientIdentity(state *tls.ConnectionState) string {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return ""
+	}
+
+	return state.PeerCertificates[0].Subject.String()
+}
+
+// handshakeDetails returns the parameters of a completed TLS handshake for the
+// connection log.
+func handshakeDetails(state tls.ConnectionState) []string {
+	details := []string{tlsVersionName(state.Version), tls.CipherSuiteName(state.CipherSuite)}
+
+	if len(state.ServerName) > 0 {
+		details = append(details, "SNI: "+state.ServerName)
+	}
+
+	if len(state.NegotiatedProtocol) > 0 {
+		details = append(details, "ALPN: "+state.NegotiatedProtocol)
+	}
+
+	if identity := clientIdentity(&state); len(identity) > 0 {
+		details = append(details, "client: "+identity)
+	}
+
+	return details
+}
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify-if-given":    tls.VerifyClientCertIfGiven,
+	"require-and-verify": tls.RequireAndVerifyClientCert,
+}
+
+// newTLSConfig creates the TLS configuration of the HTTPS, HTTP/3, TLS TCP
+// echo and gRPC servers.
+func newTLSConfig() (*tls.Config, error) {
+	clientAuth, ok := clientAuthTypes[config.https.clientAuth]
+	if !ok {
+		return nil, fmt.Errorf("invalid client authentication mode: %s", config.https.clientAuth)
+	}
+
+	tlsConfig := &tls.Config{
+		GetCertificate: config.https.certs.getCertificate,
+		ClientAuth:     clientAuth,
+	}
+
+	if err := applyTLSPolicy(tlsConfig); err != nil {
+		return nil, err
+	}
+
+	if len(config.https.clientCAFile) > 0 {
+		if !fileExists(config.https.clientCAFile) {
+			return nil, fmt.Errorf("client CA file specified but not found: %s", config.https.clientCAFile)
+		}
+
+		data, err := os.ReadFile(config.https.clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.ClientCAs = x509.NewCertPool()
+		if !tlsConfig.ClientCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in client CA file: %s", config.https.clientCAFile)
+		}
+	} else if clientAuth == tls.VerifyClientCertIfGiven || clientAuth == tls.RequireAndVerifyClientCert {
+		return nil, fmt.Errorf("client CA file must be specified for client authentication mode %s", config.https.clientAuth)
+	}
+
+	return tlsConfig, nil
+}
+
+// handleTLS returns the details of the TLS connection.
+func (s *appServer) handleTLS(w http.ResponseWriter, req *http.Request) {
+	if req.TLS == nil {
+		writeError(w, http.StatusBadRequest, errors.New("connection is not using TLS"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newTLSInfo(req))
+}
+
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	}
+
+	return fmt.Sprintf("0x%04X", version)
+}
+
+// fingerprint formats a certificate digest as colon separated hex bytes.
+func fingerprint(sum []byte) string {
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+
+	return strings.Join(parts, ":")
+}
+
+func serialString(serial *big.Int) string {
+	if serial == nil {
+		return ""
+	}
+
+	return fingerprint(serial.Bytes())
+}
+
+func sha1Sum(data []byte) []byte {
+	sum := sha1.Sum(data)
+	return sum[:]
+}
+
+func sha256Sum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
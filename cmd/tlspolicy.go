@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+type tlsPolicy struct {
+	minVersion uint16
+	maxVersion uint16
+	ciphers    []uint16
+	curves     []tls.CurveID
+}
+
+// TLS presets based on the Mozilla server side TLS recommendations.
+var tlsPresets = map[string]tlsPolicy{
+	"modern": {
+		minVersion: tls.VersionTLS13,
+		curves:     []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+	},
+	"intermediate": {
+		minVersion: tls.VersionTLS12,
+		ciphers: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		curves: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+	},
+	"legacy": {
+		minVersion: tls.VersionTLS10,
+		ciphers: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+			tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_RSA_WITH_AES_128_CBC_SHA256,
+			tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+			tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+			tls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA,
+			tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA,
+		},
+		curves: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384, tls.CurveP521},
+	},
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var tlsCurves = map[string]tls.CurveID{
+	"X25519": tls.X25519,
+	"P-256":  tls.CurveP256,
+	"P-384":  tls.CurveP384,
+	"P-521":  tls.CurveP521,
+}
+
+// applyTLSPolicy applies the preset and the TLS options to tlsConfig. The
+// options override the values of the preset.
+func applyTLSPolicy(tlsConfig *tls.Config) error {
+	var policy tlsPolicy
+	if len(config.tls.preset) > 0 {
+		var ok bool
+		if policy, ok = tlsPresets[config.tls.preset]; !ok {
+			return fmt.Errorf("invalid TLS preset: %s", config.tls.preset)
+		}
+	}
+
+	if len(config.tls.minVersion) > 0 {
+		var ok bool
+		if policy.minVersion, ok = tlsVersions[config.tls.minVersion]; !ok {
+			return fmt.Errorf("invalid minimum TLS version: %s", config.tls.minVersion)
+		}
+	}
+
+	if len(config.tls.maxVersion) > 0 {
+		var ok bool
+		if policy.maxVersion, ok = tlsVersions[config.tls.maxVersion]; !ok {
+			return fmt.Errorf("invalid maximum TLS version: %s", config.tls.maxVersion)
+		}
+	}
+
+	if policy.minVersion != 0 && policy.maxVersion != 0 && policy.minVersion > policy.maxVersion {
+		return fmt.Errorf("minimum TLS version %s is greater than maximum TLS version %s", tlsVersionName(policy.minVersion), tlsVersionName(policy.maxVersion))
+	}
+
+	if len(config.tls.ciphers) > 0 {
+		policy.ciphers = nil
+		for _, name := range config.tls.ciphers {
+			id, err := cipherSuiteID(name)
+			if err != nil {
+				return err
+			}
+
+			policy.ciphers = append(policy.ciphers, id)
+		}
+	}
+
+	if len(config.tls.curves) > 0 {
+		policy.curves = nil
+		for _, name := range config.tls.curves {
+			curve, ok := tlsCurves[strings.ToUpper(name)]
+			if !ok {
+				return fmt.Errorf("invalid TLS curve: %s", name)
+			}
+
+			policy.curves = append(policy.curves, curve)
+		}
+	}
+
+	tlsConfig.MinVersion = policy.minVersion
+	tlsConfig.MaxVersion = policy.maxVersion
+	tlsConfig.CipherSuites = policy.ciphers
+	tlsConfig.CurvePreferences = policy.curves
+	tlsConfig.NextProtos = config.tls.alpn
+	tlsConfig.SessionTicketsDisabled = !config.tls.sessionTickets
+
+	return nil
+}
+
+func cipherSuiteID(name string) (uint16, error) {
+	suites := append(tls.CipherSuites(), tls.InsecureCipherSuites()...)
+	for _, suite := range suites {
+		if suite.Name != name {
+			continue
+		}
+
+		if len(suite.SupportedVersions) == 1 && suite.SupportedVersions[0] == tls.VersionTLS13 {
+			return 0, fmt.Errorf("TLS 1.3 cipher suites are not configurable: %s", name)
+		}
+
+		return suite.ID, nil
+	}
+
+	return 0, fmt.Errorf("invalid TLS cipher suite: %s", name)
+}
+
+// logTLSPolicy logs the effective TLS policy of tlsConfig.
+func logTLSPolicy(tlsConfig *tls.Config) {
+	minVersion, maxVersion := "default", "default"
+	if tlsConfig.MinVersion != 0 {
+		minVersion = tlsVersionName(tlsConfig.MinVersion)
+	}
+	if tlsConfig.MaxVersion != 0 {
+		maxVersion = tlsVersionName(tlsConfig.MaxVersion)
+	}
+
+	ciphers := "default"
+	if len(tlsConfig.CipherSuites) > 0 {
+		names := make([]string, len(tlsConfig.CipherSuites))
+		for i, id := range tlsConfig.CipherSuites {
+			names[i] = tls.CipherSuiteName(id)
+		}
+		ciphers = strings.Join(names, ", ")
+	}
+
+	curves := "default"
+	if len(tlsConfig.CurvePreferences) > 0 {
+		names := make([]string, len(tlsConfig.CurvePreferences))
+		for i, curve := range tlsConfig.CurvePreferences {
+			names[i] = curveName(curve)
+		}
+		curves = strings.Join(names, ", ")
+	}
+
+	alpn := "default"
+	if len(tlsConfig.NextProtos) > 0 {
+		alpn = strings.Join(tlsConfig.NextProtos, ", ")
+	}
+
+	preset := config.tls.preset
+	if len(preset) == 0 {
+		preset = "none"
+	}
+
+	log.info.Printf("TLS policy: preset: %s, min. version: %s, max. version: %s, session tickets: %v\n",
+		preset, minVersion, maxVersion, !tlsConfig.SessionTicketsDisabled)
+	log.info.Printf("TLS policy: cipher suites: %s\n", ciphers)
+	log.info.Printf("TLS policy: curves: %s, ALPN: %s\n", curves, alpn)
+}
+
+func curveName(curve tls.CurveID) string {
+	for name, id := range tlsCurves {
+		if id == curve {
+			return name
+		}
+	}
+
+	return curve.String()
+}
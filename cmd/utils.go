@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"net/http"
 	"os"
+	"strings"
 )
 
 func fileExists(fileName string) bool {
 	info, err := os.Stat(fileName)
-	if os.IsNotExist(err) {
+	if err != nil {
 		return false
 	}
 
@@ -25,3 +27,8 @@ func isValidPort(p int) bool {
 
 	return true
 }
+
+// pathParam returns the part of the request path following prefix.
+func pathParam(req *http.Request, prefix string) string {
+	return strings.TrimPrefix(req.URL.Path, prefix)
+}
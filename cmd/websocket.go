@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const wsControlTimeout = 5 * time.Second // Write deadline of WebSocket control frames
+
+func (s *appServer) newUpgrader() {
+	s.upgrader = websocket.Upgrader{
+		Subprotocols:      config.ws.subprotocols,
+		EnableCompression: config.ws.compression,
+		CheckOrigin: func(req *http.Request) bool {
+			return true
+		},
+	}
+}
+
+// handleWebSocket upgrades the connection to WebSocket and echoes every text
+// and binary message back to the client.
+func (s *appServer) handleWebSocket(w http.ResponseWriter, req *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		// The upgrader has already replied with an HTTP error.
+		return
+	}
+
+	remoteAddr := conn.RemoteAddr().String()
+	log.connection(true, "WS", remoteAddr)
+
+	defer conn.Close()
+	defer log.connection(false, "WS", remoteAddr)
+
+	if len(conn.Subprotocol()) > 0 {
+		log.info.Printf("%s - WebSocket subprotocol: %s\n", remoteAddr, conn.Subprotocol())
+	}
+
+	conn.SetPingHandler(func(data string) error {
+		log.packet("ping", "WS", len(data), []byte(data), remoteAddr)
+
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(wsControlTimeout))
+		if err != nil && !errors.Is(err, websocket.ErrCloseSent) {
+			return err
+		}
+
+		return nil
+	})
+
+	conn.SetCloseHandler(func(code int, text string) error {
+		log.info.Printf("%s - WebSocket closed by client: %d %s\n", remoteAddr, code, text)
+
+		message := websocket.FormatCloseMessage(code, "")
+		if code == websocket.CloseNoStatusReceived {
+			message = websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		}
+
+		conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(wsControlTimeout))
+
+		return nil
+	})
+
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			if _, ok := err.(*websocket.CloseError); !ok {
+				log.error.Printf("websocket.ReadMessage() error: %s\n", err)
+			}
+
+			return
+		}
+
+		log.packet("read", "WS", len(data), data, remoteAddr)
+
+		if err := conn.WriteMessage(messageType, data); err != nil {
+			log.error.Printf("websocket.WriteMessage() error: %s\n", err)
+			return
+		}
+
+		log.packet("write", "WS", len(data), nil, remoteAddr)
+	}
+}
@@ -0,0 +1,4 @@
+// Package echopb contains the generated code of the gRPC echo service.
+package echopb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative echo.proto